@@ -0,0 +1,401 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// A Container is a node of a document that holds other values, such as a map
+// or a slice. Views only access documents through Containers, so the output
+// of any decoder can be viewed by adapting it with AsContainer or by
+// implementing Container directly. The values of a sequence are keyed by
+// their decimal index.
+type Container interface {
+	// Get returns the value stored under key and whether it exists.
+	Get(key string) (interface{}, bool)
+	// Set stores value under key.
+	Set(key string, value interface{}) error
+	// Delete removes the value stored under key.
+	Delete(key string) error
+	// Keys returns the keys of the container.
+	Keys() []string
+	// Index returns the value at position i of a sequence, counting back from
+	// the end if i is negative. It reports false if i is out of range or the
+	// container is not a sequence.
+	Index(i int) (interface{}, bool)
+	// Len returns the number of values in the container.
+	Len() int
+}
+
+// A SequenceContainer is a Container that can tell whether it holds a
+// sequence, such as a list, or a map. Containers implemented outside this
+// package are treated as maps unless they implement SequenceContainer and
+// report true.
+type SequenceContainer interface {
+	Container
+	// Sequence reports whether the container is a sequence.
+	Sequence() bool
+}
+
+// AsContainer adapts v to a Container. It accepts Containers,
+// map[string]interface{}, map[interface{}]interface{} as produced by YAML and
+// MessagePack decoders, []interface{} and, through reflection, any map whose
+// key type is a string kind.
+func AsContainer(v interface{}) (Container, bool) {
+	switch c := v.(type) {
+	case Container:
+		return c, true
+	case map[string]interface{}:
+		return MapContainer(c), true
+	case map[interface{}]interface{}:
+		return InterfaceMapContainer(c), true
+	case []interface{}:
+		return SliceContainer(c), true
+	case nil:
+		return nil, false
+	}
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String {
+		return reflectMapContainer{value}, true
+	}
+	return nil, false
+}
+
+// asSequence is like AsContainer but only accepts sequences.
+func asSequence(v interface{}) (Container, bool) {
+	c, ok := AsContainer(v)
+	if !ok || !isSequence(c) {
+		return nil, false
+	}
+	return c, true
+}
+
+// asMap is like AsContainer but rejects sequences.
+func asMap(v interface{}) (Container, bool) {
+	c, ok := AsContainer(v)
+	if !ok || isSequence(c) {
+		return nil, false
+	}
+	return c, true
+}
+
+// isSequence reports whether c holds a sequence rather than a map.
+func isSequence(c Container) bool {
+	switch c := c.(type) {
+	case namingContainer:
+		return isSequence(c.Container)
+	case SequenceContainer:
+		return c.Sequence()
+	}
+	return false
+}
+
+// newMapLike returns an empty map of the kind a document using c is built
+// from, for creating intermediate containers.
+func newMapLike(c Container) interface{} {
+	switch nc := c.(type) {
+	case namingContainer:
+		return newMapLike(nc.Container)
+	case pathContainer:
+		return newMapLike(nc.root)
+	}
+	if _, ok := c.(InterfaceMapContainer); ok {
+		return make(map[interface{}]interface{})
+	}
+	return make(map[string]interface{})
+}
+
+// MapContainer adapts map[string]interface{}, the form encoding/json decodes
+// objects into.
+type MapContainer map[string]interface{}
+
+func (m MapContainer) Get(key string) (interface{}, bool) {
+	v, ok := m[key]
+	return v, ok
+}
+
+func (m MapContainer) Set(key string, value interface{}) error {
+	m[key] = value
+	return nil
+}
+
+func (m MapContainer) Delete(key string) error {
+	delete(m, key)
+	return nil
+}
+
+func (m MapContainer) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (m MapContainer) Index(i int) (interface{}, bool) {
+	return nil, false
+}
+
+func (m MapContainer) Len() int {
+	return len(m)
+}
+
+// InterfaceMapContainer adapts map[interface{}]interface{}, the form YAML v2
+// and MessagePack decoders produce. Keys that are not strings are matched by
+// their fmt.Sprint representation.
+type InterfaceMapContainer map[interface{}]interface{}
+
+func (m InterfaceMapContainer) find(key string) (interface{}, bool) {
+	if _, ok := m[key]; ok {
+		return key, true
+	}
+	for k := range m {
+		if _, isString := k.(string); !isString && fmt.Sprint(k) == key {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+func (m InterfaceMapContainer) Get(key string) (interface{}, bool) {
+	if k, ok := m.find(key); ok {
+		return m[k], true
+	}
+	return nil, false
+}
+
+func (m InterfaceMapContainer) Set(key string, value interface{}) error {
+	if k, ok := m.find(key); ok {
+		m[k] = value
+	} else {
+		m[key] = value
+	}
+	return nil
+}
+
+func (m InterfaceMapContainer) Delete(key string) error {
+	if k, ok := m.find(key); ok {
+		delete(m, k)
+	}
+	return nil
+}
+
+func (m InterfaceMapContainer) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, fmt.Sprint(key))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (m InterfaceMapContainer) Index(i int) (interface{}, bool) {
+	return nil, false
+}
+
+func (m InterfaceMapContainer) Len() int {
+	return len(m)
+}
+
+// SliceContainer adapts []interface{}. It cannot grow, so Set fails for keys
+// outside the slice, and Delete sets the element to nil.
+type SliceContainer []interface{}
+
+func (s SliceContainer) index(key string) (int, error) {
+	i, err := strconv.Atoi(key)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a slice index", key)
+	}
+	if i < 0 {
+		i += len(s)
+	}
+	if i < 0 || i >= len(s) {
+		return 0, fmt.Errorf("index %s out of range for slice of length %d", key, len(s))
+	}
+	return i, nil
+}
+
+func (s SliceContainer) Get(key string) (interface{}, bool) {
+	i, err := s.index(key)
+	if err != nil {
+		return nil, false
+	}
+	return s[i], true
+}
+
+func (s SliceContainer) Set(key string, value interface{}) error {
+	i, err := s.index(key)
+	if err != nil {
+		return err
+	}
+	s[i] = value
+	return nil
+}
+
+func (s SliceContainer) Delete(key string) error {
+	return s.Set(key, nil)
+}
+
+func (s SliceContainer) Keys() []string {
+	keys := make([]string, len(s))
+	for i := range s {
+		keys[i] = strconv.Itoa(i)
+	}
+	return keys
+}
+
+func (s SliceContainer) Index(i int) (interface{}, bool) {
+	if i < 0 {
+		i += len(s)
+	}
+	if i < 0 || i >= len(s) {
+		return nil, false
+	}
+	return s[i], true
+}
+
+func (s SliceContainer) Len() int {
+	return len(s)
+}
+
+func (s SliceContainer) Sequence() bool {
+	return true
+}
+
+// reflectMapContainer adapts any map whose key type is a string kind, such as
+// map[string]string or map[MyKey]interface{}.
+type reflectMapContainer struct {
+	m reflect.Value
+}
+
+func (r reflectMapContainer) key(key string) reflect.Value {
+	return reflect.ValueOf(key).Convert(r.m.Type().Key())
+}
+
+func (r reflectMapContainer) Get(key string) (interface{}, bool) {
+	v := r.m.MapIndex(r.key(key))
+	if !v.IsValid() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+func (r reflectMapContainer) Set(key string, value interface{}) error {
+	elemType := r.m.Type().Elem()
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		v = reflect.Zero(elemType)
+	}
+	if !v.Type().AssignableTo(elemType) {
+		return fmt.Errorf("cannot store '%s' in %s", v.Type(), r.m.Type())
+	}
+	r.m.SetMapIndex(r.key(key), v)
+	return nil
+}
+
+func (r reflectMapContainer) Delete(key string) error {
+	r.m.SetMapIndex(r.key(key), reflect.Value{})
+	return nil
+}
+
+func (r reflectMapContainer) Keys() []string {
+	keys := make([]string, 0, r.m.Len())
+	for _, key := range r.m.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (r reflectMapContainer) Index(i int) (interface{}, bool) {
+	return nil, false
+}
+
+func (r reflectMapContainer) Len() int {
+	return r.m.Len()
+}
+
+// pathContainer is the map at path below root, which need not exist yet. It
+// reads as empty until then, and Set creates the missing maps on the way as
+// makeContainer does. Optional Mutable views whose parent maps are missing
+// are bound to one so that Set can create their key.
+type pathContainer struct {
+	root   Container
+	prefix []string // the path from the root of the document to root
+	path   []pathElem
+	wrap   func(Container) Container
+}
+
+// resolve returns the map at the path, creating it if create is set.
+func (c pathContainer) resolve(create bool) (Container, error) {
+	return descend(c.prefix, c.path, c.root, create, c.wrap)
+}
+
+func (c pathContainer) Get(key string) (interface{}, bool) {
+	m, err := c.resolve(false)
+	if err != nil {
+		return nil, false
+	}
+	return m.Get(key)
+}
+
+func (c pathContainer) Set(key string, value interface{}) error {
+	m, err := c.resolve(true)
+	if err != nil {
+		return err
+	}
+	return m.Set(key, value)
+}
+
+func (c pathContainer) Delete(key string) error {
+	m, err := c.resolve(false)
+	if err != nil {
+		return nil
+	}
+	return m.Delete(key)
+}
+
+func (c pathContainer) Keys() []string {
+	m, err := c.resolve(false)
+	if err != nil {
+		return nil
+	}
+	return m.Keys()
+}
+
+func (c pathContainer) Index(i int) (interface{}, bool) {
+	return nil, false
+}
+
+func (c pathContainer) Len() int {
+	m, err := c.resolve(false)
+	if err != nil {
+		return 0
+	}
+	return m.Len()
+}
@@ -0,0 +1,187 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+// recordContainer is a map-like Container implemented outside of the
+// built-in adapters, and listContainer a sequence.
+type recordContainer struct{ MapContainer }
+
+type listContainer struct{ SliceContainer }
+
+func (l listContainer) Sequence() bool { return true }
+
+func (s *ViewsSuite) TestCustomContainers(c *C) {
+	var out struct {
+		Names []*string `views:"names"`
+	}
+	data := map[string]interface{}{"names": recordContainer{MapContainer{"a": "x"}}}
+	c.Assert(Fill(&out, "", data), ErrorMatches, ".*cannot assign or convert 'views.recordContainer' to '\\[\\]\\*string' at path '.names'.*")
+
+	data = map[string]interface{}{"names": listContainer{SliceContainer{"a", nil}}}
+	c.Assert(Fill(&out, "", data), IsNil)
+	c.Assert(*out.Names[0], Equals, "a")
+	c.Assert(out.Names[1], IsNil)
+
+	var record struct {
+		Leaf leafView `views:"leaf"`
+	}
+	data = map[string]interface{}{"leaf": recordContainer{MapContainer{"leaf": "green"}}}
+	c.Assert(Fill(&record, "", data), IsNil)
+	c.Assert(record.Leaf.Leaf, Equals, "green")
+}
+
+type containerView struct {
+	Name  string       `views:"name"`
+	Port  int64        `views:"server.port,convert"`
+	Hosts []string     `views:"server.hosts"`
+	Leaf  leafView     `views:"leaf"`
+	Ratio MutableFloat `views:"server.ratio"`
+}
+
+func (s *ViewsSuite) TestFillInterfaceMap(c *C) {
+	// The shape YAML v2 and MessagePack decoders produce.
+	data := map[interface{}]interface{}{
+		"name": "yaml",
+		"server": map[interface{}]interface{}{
+			"port":  8080,
+			"hosts": []interface{}{"a", "b"},
+			"ratio": 1,
+		},
+		"leaf": map[interface{}]interface{}{"leaf": "green"},
+	}
+	out := containerView{}
+	c.Assert(Fill(&out, "", data), IsNil)
+	c.Assert(out.Name, Equals, "yaml")
+	c.Assert(out.Port, Equals, int64(8080))
+	c.Assert(out.Hosts, DeepEquals, []string{"a", "b"})
+	c.Assert(out.Leaf, Equals, leafView{"green"})
+	c.Assert(out.Ratio.Get(), Equals, float64(1))
+
+	out.Ratio.Set(0.5)
+	c.Assert(data["server"].(map[interface{}]interface{})["ratio"], Equals, 0.5)
+
+	// New maps created by Store match the document.
+	out.Leaf.Leaf = "red"
+	c.Assert(Store(&out, "copy", data), IsNil)
+	copied := data["copy"].(map[interface{}]interface{})
+	c.Assert(copied["name"], Equals, "yaml")
+	c.Assert(copied["leaf"], DeepEquals, map[interface{}]interface{}{"leaf": "red"})
+	c.Assert(copied["server"].(map[interface{}]interface{})["port"], Equals, float64(8080))
+
+	// Mutable maps and slices work with any kind of container.
+	var views struct {
+		Server MutableMap   `views:"server"`
+		Hosts  MutableSlice `views:"server.hosts"`
+	}
+	c.Assert(Fill(&views, "", data), IsNil)
+	c.Assert(views.Server.Get()["port"], Equals, 8080)
+	c.Assert(views.Hosts.Get(), DeepEquals, []interface{}{"a", "b"})
+	views.Server.Set(map[string]interface{}{"port": 9090})
+	c.Assert(data["server"], DeepEquals, map[interface{}]interface{}{"port": 9090})
+}
+
+func (s *ViewsSuite) TestInterfaceMapContainer(c *C) {
+	m := InterfaceMapContainer{1: "one", "two": 2}
+	v, ok := m.Get("1")
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, "one")
+	c.Assert(m.Keys(), DeepEquals, []string{"1", "two"})
+
+	c.Assert(m.Set("1", "uno"), IsNil)
+	c.Assert(m[1], Equals, "uno")
+	c.Assert(m.Delete("1"), IsNil)
+	c.Assert(m.Len(), Equals, 1)
+	_, ok = m.Index(0)
+	c.Assert(ok, Equals, false)
+}
+
+type labelKey string
+
+func (s *ViewsSuite) TestReflectMapContainer(c *C) {
+	labels := map[labelKey]string{"env": "prod"}
+	container, ok := AsContainer(labels)
+	c.Assert(ok, Equals, true)
+	v, ok := container.Get("env")
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, "prod")
+	c.Assert(container.Set("tier", "web"), IsNil)
+	c.Assert(labels["tier"], Equals, "web")
+	c.Assert(container.Set("tier", 1), ErrorMatches, "cannot store 'int' in map\\[views.labelKey\\]string")
+	c.Assert(container.Keys(), DeepEquals, []string{"env", "tier"})
+	c.Assert(container.Delete("env"), IsNil)
+	c.Assert(container.Len(), Equals, 1)
+
+	type view struct {
+		Tier string `views:"tier"`
+	}
+	out := view{}
+	c.Assert(Fill(&out, "", labels), IsNil)
+	c.Assert(out.Tier, Equals, "web")
+
+	_, ok = AsContainer(map[int]string{})
+	c.Assert(ok, Equals, false)
+	err := Fill(&out, "", 5)
+	c.Assert(err, ErrorMatches, ".*cannot view 'int', it is not a container.*")
+}
+
+func (s *ViewsSuite) TestSliceContainer(c *C) {
+	items := SliceContainer{"a", "b", "c"}
+	v, ok := items.Get("1")
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, "b")
+	v, ok = items.Index(-1)
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, "c")
+	_, ok = items.Get("x")
+	c.Assert(ok, Equals, false)
+	c.Assert(items.Keys(), DeepEquals, []string{"0", "1", "2"})
+
+	c.Assert(items.Set("-1", "z"), IsNil)
+	c.Assert(items[2], Equals, "z")
+	c.Assert(items.Set("3", "d"), ErrorMatches, "index 3 out of range for slice of length 3")
+	c.Assert(items.Delete("0"), IsNil)
+	c.Assert(items[0], IsNil)
+
+	// A slice can be the root of a document.
+	type view struct {
+		First string   `views:"[0].name"`
+		Last  leafView `views:"[-1]"`
+	}
+	doc := []interface{}{
+		map[string]interface{}{"name": "first"},
+		map[string]interface{}{"leaf": "last"},
+	}
+	out := view{}
+	c.Assert(Fill(&out, "", doc), IsNil)
+	c.Assert(out.First, Equals, "first")
+	c.Assert(out.Last, Equals, leafView{"last"})
+
+	// Keys into a slice are its indexes.
+	leaf := leafView{}
+	c.Assert(Fill(&leaf, "1", doc), IsNil)
+	c.Assert(leaf.Leaf, Equals, "last")
+}
@@ -0,0 +1,155 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// A Converter turns a value found in a document into a value of the type it
+// was registered for.
+type Converter func(v interface{}) (interface{}, error)
+
+type converterEntry struct {
+	from reflect.Type
+	fn   Converter
+}
+
+// converters maps the type converted to onto the converters for it, in the
+// order they were added.
+type converters map[reflect.Type][]converterEntry
+
+// find returns the converter from a value of type from to type to. A
+// converter registered for exactly from is preferred over one registered for
+// an interface from implements, and later converters win over earlier ones.
+func (c converters) find(from, to reflect.Type) Converter {
+	entries := c[to]
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].from == from {
+			return entries[i].fn
+		}
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].from.Kind() == reflect.Interface && from.Implements(entries[i].from) {
+			return entries[i].fn
+		}
+	}
+	return nil
+}
+
+func (c converters) add(from, to reflect.Type, fn Converter) {
+	if from == nil || to == nil || fn == nil {
+		panic("views: converter with nil type or function")
+	}
+	c[to] = append(c[to], converterEntry{from, fn})
+}
+
+var registry = struct {
+	sync.RWMutex
+	converters converters
+}{converters: converters{}}
+
+// RegisterConverter registers fn to convert values of type from found in a
+// document into fields of type to, for every Fill. Converters are consulted
+// whenever a value cannot be assigned to its field as is, before the convert
+// option is considered, so they also apply to fields without it. The from
+// type may be an interface, such as interface{} to convert from any value.
+func RegisterConverter(from, to reflect.Type, fn Converter) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.converters.add(from, to, fn)
+}
+
+// RegisterConverterFunc is RegisterConverter for a typed function.
+func RegisterConverterFunc[From, To any](fn func(From) (To, error)) {
+	RegisterConverter(typeFor[From](), typeFor[To](), converterFunc(fn))
+}
+
+// WithConverter is like RegisterConverter but only applies to a single Fill,
+// taking precedence over registered converters.
+func WithConverter(from, to reflect.Type, fn Converter) Option {
+	return func(f *filler) {
+		if f.converters == nil {
+			f.converters = converters{}
+		}
+		f.converters.add(from, to, fn)
+	}
+}
+
+// WithConverterFunc is WithConverter for a typed function.
+func WithConverterFunc[From, To any](fn func(From) (To, error)) Option {
+	return WithConverter(typeFor[From](), typeFor[To](), converterFunc(fn))
+}
+
+func converterFunc[From, To any](fn func(From) (To, error)) Converter {
+	return func(v interface{}) (interface{}, error) {
+		return fn(v.(From))
+	}
+}
+
+func typeFor[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// converter returns the converter for the Fill call from a value of type
+// from to type to, or nil if there is none.
+func (f *filler) converter(from, to reflect.Type) Converter {
+	if conv := f.converters.find(from, to); conv != nil {
+		return conv
+	}
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.converters.find(from, to)
+}
+
+// convertValue stores the result of converting v with conv into dst. Errors
+// are passed through fail.
+func (f *filler) convertValue(dst reflect.Value, v interface{}, conv Converter, field *field, prefix []string, leaf string, owner reflect.Type) error {
+	dstType := dst.Type()
+	out, err := conv(v)
+	if err == nil && out == nil && !canBeNil(dstType) {
+		err = fmt.Errorf("converter returned nil")
+	} else if err == nil && out != nil && !reflect.TypeOf(out).AssignableTo(dstType) {
+		err = fmt.Errorf("converter returned '%s'", reflect.TypeOf(out))
+	}
+	if err != nil {
+		return f.fail(ViewError{
+			Reason:   fmt.Sprintf("cannot convert '%s' to '%s' %s: %s", reflect.TypeOf(v), dstType, location(prefix, leaf, owner), err),
+			Kind:     ConversionFailed,
+			Struct:   owner,
+			Field:    field.goName,
+			Path:     fullPath(prefix, nil, leaf),
+			Expected: dstType,
+			Actual:   reflect.TypeOf(v),
+			Err:      err,
+		})
+	}
+	if out == nil {
+		dst.Set(reflect.Zero(dstType))
+	} else {
+		dst.Set(reflect.ValueOf(out))
+	}
+	return nil
+}
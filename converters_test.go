@@ -0,0 +1,100 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type celsius float64
+
+type converterView struct {
+	Addr  net.IP   `views:"addr"`
+	Temp  celsius  `views:"temp"`
+	Count int64    `views:"count,convert"`
+	Addrs []net.IP `views:"addrs,optional"`
+}
+
+func init() {
+	RegisterConverterFunc(func(s string) (net.IP, error) {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address '%s'", s)
+		}
+		return ip, nil
+	})
+	RegisterConverter(reflect.TypeOf(""), reflect.TypeOf(celsius(0)), func(v interface{}) (interface{}, error) {
+		var c float64
+		_, err := fmt.Sscanf(v.(string), "%gC", &c)
+		return celsius(c), err
+	})
+}
+
+func (s *ViewsSuite) TestFillConverters(c *C) {
+	data := s.getData([]byte(`{ "addr": "10.0.0.1", "temp": "21.5C", "count": 2000.6, "addrs": ["::1"] }`))
+	out := converterView{}
+	c.Assert(Fill(&out, "", data), IsNil)
+	c.Assert(out.Addr.Equal(net.ParseIP("10.0.0.1")), Equals, true)
+	c.Assert(out.Temp, Equals, celsius(21.5))
+	c.Assert(out.Count, Equals, int64(2000))
+	c.Assert(out.Addrs, HasLen, 1)
+	c.Assert(out.Addrs[0].Equal(net.IPv6loopback), Equals, true)
+
+	// Per-call converters win over reflection and registered converters.
+	round := WithConverterFunc(func(f float64) (int64, error) { return int64(math.Round(f)), nil })
+	freezing := WithConverter(reflect.TypeOf((*interface{})(nil)).Elem(), reflect.TypeOf(celsius(0)), func(interface{}) (interface{}, error) {
+		return celsius(0), nil
+	})
+	c.Assert(Fill(&out, "", data, round, freezing), IsNil)
+	c.Assert(out.Count, Equals, int64(2001))
+	c.Assert(out.Temp, Equals, celsius(0))
+
+	// Converters only apply to the call they are passed to.
+	c.Assert(Fill(&out, "", data), IsNil)
+	c.Assert(out.Count, Equals, int64(2000))
+
+	v, err := Get[celsius](data, "temp", freezing)
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, celsius(0))
+}
+
+func (s *ViewsSuite) TestFillConvertersBad(c *C) {
+	data := s.getData([]byte(`{ "addr": "nope", "temp": "21.5C", "count": 1 }`))
+	err := Fill(&converterView{}, "", data)
+	c.Assert(err, ErrorMatches, "view error - cannot convert 'string' to 'net.IP' at path '.addr' in struct of type views.converterView: invalid IP address 'nope'")
+	c.Assert(err.(ViewError).Kind, Equals, ConversionFailed)
+	c.Assert(errors.Unwrap(err), ErrorMatches, "invalid IP address 'nope'")
+
+	data["addr"] = "::1"
+	wrong := WithConverter(reflect.TypeOf(""), reflect.TypeOf(celsius(0)), func(interface{}) (interface{}, error) {
+		return 1.5, nil
+	})
+	err = Fill(&converterView{}, "", data, wrong)
+	c.Assert(err, ErrorMatches, ".*cannot convert 'string' to 'views.celsius' at path '.temp' .*: converter returned 'float64'")
+}
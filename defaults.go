@@ -0,0 +1,163 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// parseDefault parses the value of a default tag option into a value of type
+// typ. Scalars are written as they would be in Go source without quotes, and
+// times, durations and sizes as they would be in a document. The format and
+// layout of opts apply to times and durations, and with a numeric format the
+// default is the number a document would hold, such as "30" for a duration
+// with format=s. Slices, maps and structs are written as JSON
+// and filled the way Fill would fill them from a document, or for slices as a
+// list of scalars separated by '|'.
+func parseDefault(s string, typ reflect.Type, opts *field) (reflect.Value, error) {
+	out := reflect.New(typ).Elem()
+	var v interface{} = s
+	if (typ == timeType || typ == durationType) && opts.format != "" && opts.format != "rfc3339" {
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			v = n
+		}
+	}
+	if ok, err := fillBuiltin(out, v, opts); ok {
+		return out, err
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		elem, err := parseDefault(s, typ.Elem(), opts)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out.Set(reflect.New(typ.Elem()))
+		out.Elem().Set(elem)
+	case reflect.String:
+		out.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out.SetFloat(n)
+	case reflect.Slice, reflect.Map, reflect.Struct:
+		if strings.HasPrefix(s, "[") || strings.HasPrefix(s, "{") {
+			var doc interface{}
+			if err := json.Unmarshal([]byte(s), &doc); err != nil {
+				return reflect.Value{}, err
+			}
+			if err := (&filler{}).assignValue(out, doc, &convertField, nil, "default", nil); err != nil {
+				return reflect.Value{}, err
+			}
+		} else if typ.Kind() == reflect.Slice {
+			items := strings.Split(s, "|")
+			out.Set(reflect.MakeSlice(typ, len(items), len(items)))
+			for i, item := range items {
+				elem, err := parseDefault(item, typ.Elem(), opts)
+				if err != nil {
+					return reflect.Value{}, err
+				}
+				out.Index(i).Set(elem)
+			}
+		} else {
+			return reflect.Value{}, fmt.Errorf("expected a JSON default for '%s'", typ)
+		}
+	default:
+		return reflect.Value{}, fmt.Errorf("defaults are not supported for '%s'", typ)
+	}
+	return out, nil
+}
+
+// cloneValue returns a deep copy of v, so that a default shared by every Fill
+// of a type cannot be modified through one of the filled structs.
+func cloneValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(cloneValue(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(cloneValue(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(cloneValue(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), cloneValue(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(cloneValue(v.Field(i)))
+			}
+		}
+		return c
+	}
+	return v
+}
@@ -0,0 +1,155 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type defaultsView struct {
+	Timeout  int64             `views:"timeout,default=30"`
+	Ratio    float64           `views:"ratio,default=0.5"`
+	Enabled  bool              `views:"enabled,default=true"`
+	Name     string            `views:"name,default=a, b"`
+	Wait     time.Duration     `views:"wait,default=1m30s"`
+	Hosts    []string          `views:"hosts,default=a|b"`
+	Ports    []int64           `views:"ports,default=[80, 443]"`
+	Labels   map[string]string `views:"labels,default={\"env\": \"dev\"}"`
+	Leaf     leafView          `views:"leaf,default={\"leaf\": \"green\"}"`
+	Pointer  *int64            `views:"pointer,default=7"`
+	Deep     string            `views:"missing.deep,default=deep"`
+	Required string            `views:"required"`
+}
+
+func (s *ViewsSuite) TestFillDefaults(c *C) {
+	data := s.getData([]byte(`{ "timeout": 10, "wait": null, "required": "yes" }`))
+	out := defaultsView{}
+	c.Assert(Fill(&out, "", data), ErrorMatches, ".*cannot assign or convert 'float64' to 'int64'.*")
+
+	data["timeout"] = nil
+	c.Assert(Fill(&out, "", data), IsNil)
+	c.Assert(out, DeepEquals, defaultsView{
+		Timeout:  30,
+		Ratio:    0.5,
+		Enabled:  true,
+		Name:     "a, b",
+		Wait:     90 * time.Second,
+		Hosts:    []string{"a", "b"},
+		Ports:    []int64{80, 443},
+		Labels:   map[string]string{"env": "dev"},
+		Leaf:     leafView{"green"},
+		Pointer:  out.Pointer,
+		Deep:     "deep",
+		Required: "yes",
+	})
+	c.Assert(*out.Pointer, Equals, int64(7))
+
+	// Every Fill gets its own copy of the default.
+	out.Hosts[0] = "changed"
+	*out.Pointer = 8
+	again := defaultsView{}
+	c.Assert(Fill(&again, "", data), IsNil)
+	c.Assert(again.Hosts, DeepEquals, []string{"a", "b"})
+	c.Assert(*again.Pointer, Equals, int64(7))
+
+	// Present values win over defaults.
+	data["hosts"] = []interface{}{"c"}
+	data["leaf"] = map[string]interface{}{"leaf": "red"}
+	c.Assert(Fill(&out, "", data), IsNil)
+	c.Assert(out.Hosts, DeepEquals, []string{"c"})
+	c.Assert(out.Leaf, Equals, leafView{"red"})
+}
+
+func (s *ViewsSuite) TestFillFormatDefaults(c *C) {
+	type view struct {
+		Timeout time.Duration   `views:"timeout,format=s,default=30"`
+		Created time.Time       `views:"created,format=unix_ms,default=0"`
+		Expiry  *time.Time      `views:"expiry,format=unix,default=1.5"`
+		Waits   []time.Duration `views:"waits,format=ms,default=10|20"`
+	}
+	out := view{}
+	c.Assert(Fill(&out, "", map[string]interface{}{}), IsNil)
+	c.Assert(out.Timeout, Equals, 30*time.Second)
+	c.Assert(out.Created.Equal(time.UnixMilli(0)), Equals, true)
+	c.Assert(out.Expiry.Equal(time.UnixMilli(1500)), Equals, true)
+	c.Assert(out.Waits, DeepEquals, []time.Duration{10 * time.Millisecond, 20 * time.Millisecond})
+
+	// Present values still use the format.
+	c.Assert(Fill(&out, "", map[string]interface{}{"timeout": 5.0}), IsNil)
+	c.Assert(out.Timeout, Equals, 5*time.Second)
+
+	type badNumber struct {
+		Timeout time.Duration `views:"timeout,format=s,default=soon"`
+	}
+	err := Fill(&badNumber{}, "", map[string]interface{}{})
+	c.Assert(err, ErrorMatches, ".*invalid default 'soon' on field Timeout.*")
+}
+
+func (s *ViewsSuite) TestBadDefaults(c *C) {
+	data := map[string]interface{}{}
+
+	type badInt struct {
+		Value int8 `views:"value,default=300"`
+	}
+	err := Fill(&badInt{}, "", data)
+	c.Assert(err, ErrorMatches, ".*invalid default '300' on field Value of views.badInt: .*value out of range.*")
+	c.Assert(err.(ViewError).Kind, Equals, InvalidTag)
+
+	type badDuration struct {
+		Value time.Duration `views:"value,default=soon"`
+	}
+	err = Fill(&badDuration{}, "", data)
+	c.Assert(err, ErrorMatches, ".*invalid default 'soon' on field Value.*")
+
+	type badStruct struct {
+		Value leafView `views:"value,default={\"leaf\": 1}"`
+	}
+	err = Fill(&badStruct{}, "", data)
+	c.Assert(err, ErrorMatches, ".*invalid default .* cannot assign or convert 'float64' to 'string'.*")
+
+	type badView struct {
+		Value MutableFloat `views:"value,default=1"`
+	}
+	err = Fill(&badView{}, "", data)
+	c.Assert(err, ErrorMatches, ".*defaults are not supported for view fields.*")
+
+	type badSlice struct {
+		Value []int `views:"value,default=1|x"`
+	}
+	err = Fill(&badSlice{}, "", data)
+	c.Assert(err, ErrorMatches, ".*invalid default '1\\|x'.*")
+}
+
+func (s *ViewsSuite) TestTagOptionsDefault(c *C) {
+	opts := tagOptions("optional,default=a,convert")
+	def, ok := opts.Default()
+	c.Assert(ok, Equals, true)
+	c.Assert(def, Equals, "a,convert")
+	c.Assert(opts.Contains("optional"), Equals, true)
+	c.Assert(opts.Contains("convert"), Equals, false)
+
+	_, ok = tagOptions("optional").Default()
+	c.Assert(ok, Equals, false)
+}
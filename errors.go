@@ -0,0 +1,195 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// An ErrorKind classifies a ViewError.
+type ErrorKind int
+
+const (
+	// UnknownError is the kind of a ViewError that was built without one.
+	UnknownError ErrorKind = iota
+	// MissingKey means a key or slice index was not present in the document.
+	MissingKey
+	// TypeMismatch means a value in the document has a type the field cannot
+	// hold.
+	TypeMismatch
+	// NotAContainer means a path descended into a value that is not a map or
+	// slice.
+	NotAContainer
+	// ConversionFailed means a field allowed conversion but the value could
+	// not be converted to the field's type.
+	ConversionFailed
+	// UnknownInterface means a field has an interface type views does not
+	// know how to bind.
+	UnknownInterface
+	// InvalidTag means a views struct tag could not be parsed.
+	InvalidTag
+	// InvalidPath means a path could not be parsed.
+	InvalidPath
+	// Unsupported means the operation cannot be done on the document, such
+	// as storing a channel or setting the root of a document.
+	Unsupported
+	// NullValue means the document holds null for a field that cannot be
+	// nil and is neither optional nor nullable.
+	NullValue
+	// ValidationFailed means a value was filled but violates one of the
+	// field's validation options, such as min or pattern.
+	ValidationFailed
+	// DuplicatePath means two fields of a view struct map to the same path
+	// in the document and neither dominates the other, so both are left
+	// out. It is reported by CheckView.
+	DuplicatePath
+)
+
+var errorKindNames = []string{
+	UnknownError:     "UnknownError",
+	MissingKey:       "MissingKey",
+	TypeMismatch:     "TypeMismatch",
+	NotAContainer:    "NotAContainer",
+	ConversionFailed: "ConversionFailed",
+	UnknownInterface: "UnknownInterface",
+	InvalidTag:       "InvalidTag",
+	InvalidPath:      "InvalidPath",
+	Unsupported:      "Unsupported",
+	NullValue:        "NullValue",
+	ValidationFailed: "ValidationFailed",
+	DuplicatePath:    "DuplicatePath",
+}
+
+func (k ErrorKind) String() string {
+	if k >= 0 && int(k) < len(errorKindNames) {
+		return errorKindNames[k]
+	}
+	return fmt.Sprintf("ErrorKind(%d)", int(k))
+}
+
+// A ViewError describes why a value could not be filled. Path is the dotted
+// path from the root of the document to the value, and Expected and Actual
+// are the Go type the view needed and the type found in the document, when
+// known. Struct and Field name the struct field being filled, and Err is the
+// underlying cause, if any.
+type ViewError struct {
+	Reason   string
+	Kind     ErrorKind
+	Path     string
+	Struct   reflect.Type
+	Field    string
+	Expected reflect.Type
+	Actual   reflect.Type
+	Err      error
+}
+
+func (v ViewError) Error() string {
+	return fmt.Sprintf("view error - %s", v.Reason)
+}
+
+func (v ViewError) Unwrap() error {
+	return v.Err
+}
+
+// Segments returns Path split into its dot-separated segments, such as
+// ["a", "b", "e[3]", "field1"].
+func (v ViewError) Segments() []string {
+	if v.Path == "" {
+		return nil
+	}
+	return splitPath(v.Path)
+}
+
+// JSONPointer returns Path as an RFC 6901 JSON pointer, such as
+// "/a/b/e/3/field1".
+func (v ViewError) JSONPointer() string {
+	var b strings.Builder
+	for _, segment := range v.Segments() {
+		for _, token := range pointerTokens(segment) {
+			b.WriteByte('/')
+			b.WriteString(strings.NewReplacer("~", "~0", "/", "~1").Replace(token))
+		}
+	}
+	return b.String()
+}
+
+// pointerTokens splits a path segment such as "e[3]" or `labels["env"]` into
+// its JSON pointer reference tokens.
+func pointerTokens(segment string) []string {
+	i := strings.IndexByte(segment, '[')
+	if i == -1 {
+		return []string{segment}
+	}
+	var tokens []string
+	if i > 0 {
+		tokens = append(tokens, segment[:i])
+	}
+	for rest := segment[i:]; rest != ""; {
+		if rest[0] != '[' {
+			return []string{segment}
+		}
+		token := rest[1:]
+		if quoted, err := strconv.QuotedPrefix(token); err == nil {
+			token, _ = strconv.Unquote(quoted)
+			rest = rest[1+len(quoted):]
+		} else if end := strings.IndexByte(rest, ']'); end != -1 {
+			token = rest[1:end]
+			rest = rest[end:]
+		} else {
+			return []string{segment}
+		}
+		if !strings.HasPrefix(rest, "]") {
+			return []string{segment}
+		}
+		tokens = append(tokens, token)
+		rest = rest[1:]
+	}
+	return tokens
+}
+
+// ViewErrors is returned by FillAll and lists every value that could not be
+// filled. It unwraps to the individual ViewErrors for errors.Is and
+// errors.As.
+type ViewErrors []ViewError
+
+func (v ViewErrors) Error() string {
+	if len(v) == 1 {
+		return v[0].Error()
+	}
+	reasons := make([]string, len(v))
+	for i, err := range v {
+		reasons[i] = err.Reason
+	}
+	return fmt.Sprintf("view error - %d problems: %s", len(v), strings.Join(reasons, "; "))
+}
+
+func (v ViewErrors) Unwrap() []error {
+	errs := make([]error, len(v))
+	for i, err := range v {
+		errs[i] = err
+	}
+	return errs
+}
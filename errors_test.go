@@ -0,0 +1,120 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+
+	. "gopkg.in/check.v1"
+)
+
+func (s *ViewsSuite) TestViewErrorKinds(c *C) {
+	data := s.getData([]byte(`
+	{
+		"a": {
+			"b": {
+				"e": [{ "field1": "x" }, { "field1": 3 }, { "other": true }],
+				"name": "foo"
+			}
+		}
+	}`))
+
+	type elems struct {
+		Elems []sliceElemView `views:"a.b.e"`
+	}
+	var viewErr ViewError
+	err := Fill(&elems{}, "", data)
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Kind, Equals, TypeMismatch)
+	c.Assert(viewErr.Path, Equals, "a.b.e[1].field1")
+	c.Assert(viewErr.Segments(), DeepEquals, []string{"a", "b", "e[1]", "field1"})
+	c.Assert(viewErr.JSONPointer(), Equals, "/a/b/e/1/field1")
+	c.Assert(viewErr.Struct, Equals, reflect.TypeOf(sliceElemView{}))
+	c.Assert(viewErr.Field, Equals, "Field1")
+
+	err = FillAll(&elems{}, "", data)
+	var viewErrs ViewErrors
+	c.Assert(errors.As(err, &viewErrs), Equals, true)
+	c.Assert(viewErrs, HasLen, 2)
+	c.Assert(viewErrs[1].Kind, Equals, MissingKey)
+	c.Assert(viewErrs[1].Path, Equals, "a.b.e[2].field1")
+
+	type convert struct {
+		Name bool `views:"a.b.name,convert"`
+	}
+	err = Fill(&convert{}, "", data)
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Kind, Equals, ConversionFailed)
+	c.Assert(viewErr.Struct, Equals, reflect.TypeOf(convert{}))
+	c.Assert(viewErr.Field, Equals, "Name")
+
+	type notAContainer struct {
+		Name string `views:"a.b.name.first"`
+	}
+	err = Fill(&notAContainer{}, "", data)
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Kind, Equals, NotAContainer)
+	c.Assert(viewErr.Path, Equals, "a.b.name")
+	c.Assert(viewErr.Field, Equals, "Name")
+	c.Assert(viewErr.Expected, Equals, mapType)
+	c.Assert(viewErr.Actual, Equals, reflect.TypeOf(""))
+
+	type unknown struct {
+		Name interface{ Unknown() } `views:"a.b.name"`
+	}
+	err = Fill(&unknown{}, "", data)
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Kind, Equals, UnknownInterface)
+
+	type badTag struct {
+		Name string `views:"a.b.e[one].field1"`
+	}
+	err = Fill(&badTag{}, "", data)
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Kind, Equals, InvalidTag)
+	c.Assert(viewErr.Field, Equals, "Name")
+	c.Assert(errors.Unwrap(err), NotNil)
+	var numErr *strconv.NumError
+	c.Assert(errors.As(err, &numErr), Equals, true)
+	c.Assert(numErr.Num, Equals, "one")
+
+	err = Fill(&elems{}, "a.b.missing", data)
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Kind, Equals, MissingKey)
+	c.Assert(viewErr.Path, Equals, "a.b.missing")
+	c.Assert(viewErr.Segments(), DeepEquals, []string{"a", "b", "missing"})
+
+	err = Fill(&elems{}, "", nil)
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Kind, Equals, NotAContainer)
+	c.Assert(err, ErrorMatches, "view error - cannot view 'null', it is not a container")
+}
+
+func (s *ViewsSuite) TestErrorKindString(c *C) {
+	c.Assert(MissingKey.String(), Equals, "MissingKey")
+	c.Assert(Unsupported.String(), Equals, "Unsupported")
+	c.Assert(ErrorKind(100).String(), Equals, "ErrorKind(100)")
+	c.Assert(ViewError{Path: "a/b.~c"}.JSONPointer(), Equals, "/a~1b/~0c")
+}
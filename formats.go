@@ -0,0 +1,277 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ByteSize is a number of bytes, written in documents as a number or as a
+// string with a unit such as "512MB" or "1.5GiB". KB, MB, GB, TB and PB are
+// powers of 1000, and KiB, MiB, GiB, TiB and PiB powers of 1024.
+type ByteSize int64
+
+var byteUnits = []struct {
+	suffix string
+	size   ByteSize
+}{
+	{"PiB", 1 << 50}, {"PB", 1e15},
+	{"TiB", 1 << 40}, {"TB", 1e12},
+	{"GiB", 1 << 30}, {"GB", 1e9},
+	{"MiB", 1 << 20}, {"MB", 1e6},
+	{"KiB", 1 << 10}, {"KB", 1e3},
+	{"B", 1},
+}
+
+// ParseByteSize parses a size such as "512MB". Units are case-insensitive and
+// a number without a unit is a number of bytes.
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.' && r != '-' && r != '+'
+	})
+	number, unit := s, ""
+	if i >= 0 {
+		number, unit = s[:i], strings.TrimSpace(s[i:])
+	}
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size '%s'", s)
+	}
+	size := ByteSize(1)
+	if unit != "" {
+		size = 0
+		for _, u := range byteUnits {
+			if strings.EqualFold(unit, u.suffix) {
+				size = u.size
+				break
+			}
+		}
+		if size == 0 {
+			return 0, fmt.Errorf("unknown unit '%s' in size '%s'", unit, s)
+		}
+	}
+	return ByteSize(math.Round(n * float64(size))), nil
+}
+
+// String formats the size with the largest unit that divides it exactly.
+func (b ByteSize) String() string {
+	for _, u := range byteUnits {
+		if b != 0 && b%u.size == 0 {
+			return strconv.FormatInt(int64(b/u.size), 10) + u.suffix
+		}
+	}
+	return "0B"
+}
+
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = size
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+var durationType = reflect.TypeOf(time.Duration(0))
+var byteSizeType = reflect.TypeOf(ByteSize(0))
+
+// timeFormats are the values of the format option for times stored as
+// numbers, and the unit of each.
+var timeFormats = map[string]time.Duration{
+	"unix":    time.Second,
+	"unix_ms": time.Millisecond,
+	"unix_us": time.Microsecond,
+	"unix_ns": time.Nanosecond,
+}
+
+// durationFormats are the values of the format option for durations stored
+// as numbers, and the unit of each.
+var durationFormats = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// formatTarget returns the type the format and layout options of a field of
+// type typ apply to, looking through pointers, slices, maps and Mutable.
+func formatTarget(typ reflect.Type) reflect.Type {
+	for {
+		if reflect.PointerTo(typ).Implements(mutableBinderType) {
+			typ = reflect.New(typ).Interface().(mutableBinder).viewType()
+			continue
+		}
+		switch typ.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+			typ = typ.Elem()
+			continue
+		}
+		return typ
+	}
+}
+
+// checkFormat reports whether the format and layout options are valid for
+// typ.
+func checkFormat(typ reflect.Type, format, layout string) error {
+	switch {
+	case format == "" && layout == "":
+		return nil
+	case layout != "" && typ != timeType:
+		return fmt.Errorf("layout is not supported for '%s'", typ)
+	case format == "":
+		return nil
+	case typ == timeType && layout != "":
+		return fmt.Errorf("format and layout cannot both be set")
+	case typ == timeType && (format == "rfc3339" || timeFormats[format] != 0):
+		return nil
+	case typ == durationType && durationFormats[format] != 0:
+		return nil
+	case typ == timeType || typ == durationType:
+		return fmt.Errorf("unknown format '%s' for '%s'", format, typ)
+	}
+	return fmt.Errorf("format is not supported for '%s'", typ)
+}
+
+// fillBuiltin fills dst from v if dst is a time.Time, time.Duration or
+// ByteSize, which views converts itself. It reports whether dst had one of
+// those types.
+//
+// Times are read from RFC 3339 strings, or strings in the field's layout, and
+// from numbers of seconds since the Unix epoch or of the unit given by the
+// field's format. Durations are read from strings accepted by
+// time.ParseDuration and from numbers of nanoseconds or of the unit given by
+// the format. Sizes are read as by ParseByteSize, or from numbers of bytes.
+func fillBuiltin(dst reflect.Value, v interface{}, field *field) (bool, error) {
+	var out interface{}
+	var err error
+	switch dst.Type() {
+	case timeType:
+		out, err = parseTime(v, field.format, field.layout)
+	case durationType:
+		out, err = parseDuration(v, field.format)
+	case byteSizeType:
+		if s, ok := v.(string); ok {
+			out, err = ParseByteSize(s)
+		} else if n, ok := toInt64(v); ok {
+			out = ByteSize(n)
+		} else {
+			err = fmt.Errorf("expected a size, not '%s'", reflect.TypeOf(v))
+		}
+	default:
+		return false, nil
+	}
+	if err == nil {
+		dst.Set(reflect.ValueOf(out))
+	}
+	return true, err
+}
+
+func parseTime(v interface{}, format, layout string) (time.Time, error) {
+	if s, ok := v.(string); ok {
+		if format != "" && format != "rfc3339" {
+			return time.Time{}, fmt.Errorf("expected a number for format '%s', not '%s'", format, s)
+		}
+		if layout == "" {
+			layout = time.RFC3339Nano
+		}
+		return time.Parse(layout, s)
+	}
+	n, ok := toFloat64(v)
+	if !ok {
+		return time.Time{}, fmt.Errorf("expected a string or number, not '%s'", reflect.TypeOf(v))
+	}
+	if format == "rfc3339" || layout != "" {
+		return time.Time{}, fmt.Errorf("expected a string, not %v", n)
+	}
+	unit := time.Second
+	if format != "" {
+		unit = timeFormats[format]
+	}
+	if i, ok := toInt64(v); ok {
+		return time.Unix(0, 0).Add(time.Duration(i) * unit).UTC(), nil
+	}
+	whole, frac := math.Modf(n)
+	return time.Unix(0, int64(whole)*int64(unit)+int64(frac*float64(unit))).UTC(), nil
+}
+
+func parseDuration(v interface{}, format string) (time.Duration, error) {
+	if s, ok := v.(string); ok {
+		return time.ParseDuration(s)
+	}
+	n, ok := toFloat64(v)
+	if !ok {
+		return 0, fmt.Errorf("expected a string or number, not '%s'", reflect.TypeOf(v))
+	}
+	unit := time.Nanosecond
+	if format != "" {
+		unit = durationFormats[format]
+	}
+	return time.Duration(math.Round(n * float64(unit))), nil
+}
+
+// storeBuiltin is the reverse of fillBuiltin. Times are stored as RFC 3339
+// strings unless the field has a format or layout, durations as strings such
+// as "1m30s" unless the field has a format, and sizes as strings such as
+// "512MB". It reports whether v had one of the types fillBuiltin handles.
+func storeBuiltin(v reflect.Value, field *field) (interface{}, bool) {
+	switch v.Type() {
+	case timeType:
+		t := v.Interface().(time.Time)
+		switch field.format {
+		case "unix":
+			return float64(t.Unix()), true
+		case "unix_ms":
+			return float64(t.UnixMilli()), true
+		case "unix_us":
+			return float64(t.UnixMicro()), true
+		case "unix_ns":
+			return float64(t.UnixNano()), true
+		}
+		if field.layout != "" {
+			return t.Format(field.layout), true
+		}
+		return t.Format(time.RFC3339Nano), true
+	case durationType:
+		d := time.Duration(v.Int())
+		if field.format != "" {
+			return float64(d) / float64(durationFormats[field.format]), true
+		}
+		return d.String(), true
+	case byteSizeType:
+		return ByteSize(v.Int()).String(), true
+	}
+	return nil, false
+}
@@ -0,0 +1,156 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type timesView struct {
+	Created  time.Time          `views:"created"`
+	Epoch    time.Time          `views:"epoch"`
+	Millis   time.Time          `views:"millis,format=unix_ms"`
+	Day      time.Time          `views:"day,layout=2006-01-02"`
+	Timeout  time.Duration      `views:"timeout"`
+	Nanos    time.Duration      `views:"nanos"`
+	Seconds  time.Duration      `views:"seconds,format=s"`
+	Size     ByteSize           `views:"size"`
+	Bytes    ByteSize           `views:"bytes"`
+	Deadline *time.Time         `views:"deadline,optional"`
+	Windows  []time.Duration    `views:"windows"`
+	Expiry   Mutable[time.Time] `views:"expiry,format=unix"`
+}
+
+func (s *ViewsSuite) TestFillTimes(c *C) {
+	data := s.getData([]byte(`
+	{
+		"created": "2024-03-01T12:30:00.5Z",
+		"epoch": 1700000000.25,
+		"millis": 1700000000123,
+		"day": "2024-03-01",
+		"timeout": "1m30s",
+		"nanos": 1500,
+		"seconds": 2.5,
+		"size": "512MB",
+		"bytes": 1024,
+		"deadline": "2024-03-02T00:00:00+01:00",
+		"windows": ["1h", "30m"],
+		"expiry": 1700000000
+	}`))
+	out := timesView{}
+	c.Assert(Fill(&out, "", data), IsNil)
+	c.Assert(out.Created.Equal(time.Date(2024, 3, 1, 12, 30, 0, 5e8, time.UTC)), Equals, true)
+	c.Assert(out.Epoch.Equal(time.Unix(1700000000, 25e7)), Equals, true)
+	c.Assert(out.Millis.Equal(time.UnixMilli(1700000000123)), Equals, true)
+	c.Assert(out.Day.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)), Equals, true)
+	c.Assert(out.Timeout, Equals, 90*time.Second)
+	c.Assert(out.Nanos, Equals, 1500*time.Nanosecond)
+	c.Assert(out.Seconds, Equals, 2500*time.Millisecond)
+	c.Assert(out.Size, Equals, ByteSize(512e6))
+	c.Assert(out.Bytes, Equals, ByteSize(1024))
+	c.Assert(out.Deadline.Equal(time.Date(2024, 3, 1, 23, 0, 0, 0, time.UTC)), Equals, true)
+	c.Assert(out.Windows, DeepEquals, []time.Duration{time.Hour, 30 * time.Minute})
+	c.Assert(out.Expiry.Get().Equal(time.Unix(1700000000, 0)), Equals, true)
+
+	// Mutables and Store write values back in the field's format.
+	out.Expiry.Set(time.Unix(1800000000, 0))
+	c.Assert(data["expiry"], Equals, float64(1800000000))
+
+	stored := make(map[string]interface{})
+	c.Assert(Store(&out, "", stored), IsNil)
+	c.Assert(stored["created"], Equals, "2024-03-01T12:30:00.5Z")
+	c.Assert(stored["millis"], Equals, float64(1700000000123))
+	c.Assert(stored["day"], Equals, "2024-03-01")
+	c.Assert(stored["timeout"], Equals, "1m30s")
+	c.Assert(stored["seconds"], Equals, 2.5)
+	c.Assert(stored["size"], Equals, "512MB")
+	c.Assert(stored["bytes"], Equals, "1KiB")
+	c.Assert(stored["windows"], DeepEquals, []interface{}{"1h0m0s", "30m0s"})
+
+	again := timesView{}
+	c.Assert(Fill(&again, "", stored), IsNil)
+	c.Assert(again.Created.Equal(out.Created), Equals, true)
+	c.Assert(again.Millis.Equal(out.Millis), Equals, true)
+	c.Assert(again.Size, Equals, out.Size)
+}
+
+func (s *ViewsSuite) TestFillTimesBad(c *C) {
+	type timeView struct {
+		Created time.Time `views:"created"`
+	}
+	err := Fill(&timeView{}, "", map[string]interface{}{"created": "yesterday"})
+	c.Assert(err, ErrorMatches, "view error - cannot convert 'string' to 'time.Time' at path '.created' in struct of type views.timeView: .*cannot parse \"yesterday\".*")
+	c.Assert(err.(ViewError).Kind, Equals, ConversionFailed)
+
+	type sizeView struct {
+		Size ByteSize `views:"size"`
+	}
+	err = Fill(&sizeView{}, "", map[string]interface{}{"size": "12 parsecs"})
+	c.Assert(err, ErrorMatches, ".*unknown unit 'parsecs' in size '12 parsecs'.*")
+
+	type millisView struct {
+		Millis time.Time `views:"millis,format=unix_ms"`
+	}
+	err = Fill(&millisView{}, "", map[string]interface{}{"millis": "2024-03-01T12:30:00Z"})
+	c.Assert(err, ErrorMatches, ".*expected a number for format 'unix_ms'.*")
+
+	type badFormat struct {
+		Wait time.Duration `views:"wait,format=fortnights"`
+	}
+	err = Fill(&badFormat{}, "", map[string]interface{}{})
+	c.Assert(err, ErrorMatches, ".*invalid format on field Wait of views.badFormat: unknown format 'fortnights' for 'time.Duration'.*")
+	c.Assert(err.(ViewError).Kind, Equals, InvalidTag)
+
+	type badLayout struct {
+		Name string `views:"name,layout=2006"`
+	}
+	err = Fill(&badLayout{}, "", map[string]interface{}{})
+	c.Assert(err, ErrorMatches, ".*layout is not supported for 'string'.*")
+}
+
+func (s *ViewsSuite) TestByteSize(c *C) {
+	for _, t := range []struct {
+		in   string
+		size ByteSize
+	}{
+		{"0", 0},
+		{"100", 100},
+		{"1.5KiB", 1536},
+		{"512mb", 512e6},
+		{"2 GiB", 2 << 30},
+		{"1PB", 1e15},
+	} {
+		size, err := ParseByteSize(t.in)
+		c.Assert(err, IsNil, Commentf(t.in))
+		c.Assert(size, Equals, t.size, Commentf(t.in))
+	}
+	_, err := ParseByteSize("MB")
+	c.Assert(err, ErrorMatches, "invalid size 'MB'")
+
+	c.Assert(ByteSize(0).String(), Equals, "0B")
+	c.Assert(ByteSize(1536).String(), Equals, "1536B")
+	c.Assert(ByteSize(3<<20).String(), Equals, "3MiB")
+	c.Assert(ByteSize(2e9).String(), Equals, "2GB")
+}
@@ -0,0 +1,80 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Get returns the value at path in doc converted to T, following the same
+// rules as a field tagged with the convert option: numbers convert between
+// Go numeric types, and maps and slices fill structs and typed slices. The
+// path and options are given the same way as to Fill, and keys are looked
+// up with the naming of WithKeyNaming.
+func Get[T any](doc interface{}, path interface{}, opts ...Option) (T, error) {
+	var out T
+	p, err := toPath(path, "Get")
+	if err != nil {
+		return out, err
+	}
+	f := newFiller(opts)
+	v, err := p.get(doc, f.keys)
+	if err != nil {
+		return out, err
+	}
+	var prefix []string
+	var leaf string
+	if n := len(p.segments); n > 0 {
+		prefix, leaf = p.segments[:n-1], p.segments[n-1]
+	}
+	if err := f.assignValue(reflect.ValueOf(&out).Elem(), v, &convertField, prefix, leaf, nil); err != nil {
+		var zero T
+		return zero, err
+	}
+	return out, nil
+}
+
+// GetOr is like Get but returns def if the value is missing or cannot be
+// converted to T.
+func GetOr[T any](doc interface{}, path interface{}, def T, opts ...Option) T {
+	if v, err := Get[T](doc, path, opts...); err == nil {
+		return v
+	}
+	return def
+}
+
+// toPath converts a base path as accepted by Fill into a Path. The fn is the
+// name of the calling function for the panic on a bad argument type.
+func toPath(basePath interface{}, fn string) (Path, error) {
+	switch basePath := basePath.(type) {
+	case string:
+		return ParsePath(basePath)
+	case []string:
+		return ParsePath(strings.Join(basePath, "."))
+	case Path:
+		return basePath, nil
+	}
+	panic(fmt.Sprintf("bad argument type to views.%s '%s'", fn, reflect.TypeOf(basePath)))
+}
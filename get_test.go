@@ -0,0 +1,101 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (s *ViewsSuite) TestGet(c *C) {
+	data := s.getData([]byte(`
+	{
+		"a": {
+			"b": { "c": 2000.12354, "name": "foo", "tags": ["x", "y"], "null": null },
+			"items": [{ "leaf": "first" }, { "leaf": "second" }]
+		}
+	}`))
+
+	f, err := Get[float64](data, "a.b.c")
+	c.Assert(err, IsNil)
+	c.Assert(f, Equals, 2000.12354)
+
+	// Numbers convert like the convert option.
+	i, err := Get[int64](data, "a.b.c")
+	c.Assert(err, IsNil)
+	c.Assert(i, Equals, int64(2000))
+
+	name, err := Get[StringTypedef](data, []string{"a", "b", "name"})
+	c.Assert(err, IsNil)
+	c.Assert(name, Equals, StringTypedef("foo"))
+
+	tags, err := Get[[]string](data, "a.b.tags")
+	c.Assert(err, IsNil)
+	c.Assert(tags, DeepEquals, []string{"x", "y"})
+
+	p, _ := ParsePath("a.items[-1]")
+	leaf, err := Get[leafView](data, p)
+	c.Assert(err, IsNil)
+	c.Assert(leaf, Equals, leafView{"second"})
+
+	ptr, err := Get[*string](data, "a.b.null")
+	c.Assert(err, IsNil)
+	c.Assert(ptr, IsNil)
+
+	doc, err := Get[map[string]interface{}](data, "")
+	c.Assert(err, IsNil)
+	c.Assert(doc, DeepEquals, data)
+
+	_, err = Get[int64](data, "a.b.name")
+	c.Assert(err, ErrorMatches, "view error - cannot assign or convert 'string' to 'int64' at path 'a.b.name'")
+	c.Assert(err.(ViewError).Kind, Equals, ConversionFailed)
+	c.Assert(err.(ViewError).Path, Equals, "a.b.name")
+
+	_, err = Get[string](data, "a.b.missing")
+	c.Assert(err, ErrorMatches, ".*no such key 'missing' at index 2 in path 'a.b.missing'.*")
+
+	_, err = Get[int64](data, "a.b.null")
+	c.Assert(err, ErrorMatches, ".*cannot assign null to 'int64' at path 'a.b.null'.*")
+
+	_, err = Get[string](data, "a.b[")
+	c.Assert(err.(ViewError).Kind, Equals, InvalidPath)
+}
+
+func (s *ViewsSuite) TestGetOr(c *C) {
+	data := s.getData([]byte(`{ "a": { "port": 8080, "host": 12 } }`))
+
+	c.Assert(GetOr(data, "a.port", 80), Equals, 8080)
+	c.Assert(GetOr(data, "a.missing", 80), Equals, 80)
+	c.Assert(GetOr(data, "a.host", "localhost"), Equals, "localhost")
+	c.Assert(GetOr(data, "a.port.deeper", int64(1)), Equals, int64(1))
+}
+
+func (s *ViewsSuite) TestGetKeyNaming(c *C) {
+	data := s.getData([]byte(`{ "server_config": { "user_id": "u1" } }`))
+
+	id, err := Get[string](data, "ServerConfig.UserID", WithKeyNaming(SnakeCase))
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, "u1")
+
+	_, err = Get[string](data, "ServerConfig.UserID")
+	c.Assert(err, ErrorMatches, "view error - .*")
+}
@@ -0,0 +1,176 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// fillMap fills the map dst from the container v entry by entry, converting
+// each key to the map's key type and filling each value as if it were a
+// field of the map's element type.
+func (f *filler) fillMap(dst reflect.Value, v interface{}, field *field, prefix []string, leaf string, owner reflect.Type) error {
+	dstType := dst.Type()
+	vType := reflect.TypeOf(v)
+	entries, ok := asMap(v)
+	if !ok {
+		return f.fail(ViewError{
+			Reason:   fmt.Sprintf("cannot assign or convert '%s' to '%s' %s", vType, dstType, location(prefix, leaf, owner)),
+			Kind:     TypeMismatch,
+			Struct:   owner,
+			Field:    field.goName,
+			Path:     fullPath(prefix, nil, leaf),
+			Expected: dstType,
+			Actual:   vType,
+		})
+	}
+	out := reflect.MakeMapWithSize(dstType, entries.Len())
+	for _, k := range entries.Keys() {
+		entryLeaf := fmt.Sprintf("%s[%q]", leaf, k)
+		key, err := parseMapKey(k, dstType.Key())
+		if err != nil {
+			return f.fail(ViewError{
+				Reason:   fmt.Sprintf("cannot convert key '%s' to '%s' %s: %s", k, dstType.Key(), location(prefix, entryLeaf, owner), err),
+				Kind:     ConversionFailed,
+				Struct:   owner,
+				Field:    field.goName,
+				Path:     fullPath(prefix, nil, entryLeaf),
+				Expected: dstType.Key(),
+				Actual:   reflect.TypeOf(k),
+				Err:      err,
+			})
+		}
+		entry, _ := entries.Get(k)
+		elem := reflect.New(dstType.Elem()).Elem()
+		failed := len(f.errs)
+		if err := f.assignValue(elem, entry, field, prefix, entryLeaf, owner); err != nil {
+			return err
+		} else if len(f.errs) > failed {
+			continue
+		}
+		out.SetMapIndex(key, elem)
+	}
+	dst.Set(out)
+	return nil
+}
+
+// parseMapKey converts a document key to the key type of a map. Like
+// encoding/json, it prefers encoding.TextUnmarshaler and otherwise accepts
+// string and integer key types.
+func parseMapKey(s string, typ reflect.Type) (reflect.Value, error) {
+	key := reflect.New(typ)
+	if u, ok := key.Interface().(encoding.TextUnmarshaler); ok {
+		return key.Elem(), u.UnmarshalText([]byte(s))
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		key.Elem().SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, typ.Bits())
+		if err != nil {
+			return key.Elem(), err
+		}
+		key.Elem().SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, typ.Bits())
+		if err != nil {
+			return key.Elem(), err
+		}
+		key.Elem().SetUint(n)
+	default:
+		return key.Elem(), fmt.Errorf("unsupported map key type")
+	}
+	return key.Elem(), nil
+}
+
+// formatMapKey is the reverse of parseMapKey for Store.
+func formatMapKey(key reflect.Value) (string, error) {
+	if key.Kind() == reflect.String {
+		return key.String(), nil
+	}
+	if m, ok := key.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		return string(text), err
+	}
+	switch key.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(key.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(key.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported map key type")
+}
+
+// storeMap converts the map v into a new map made like parent, storing each
+// entry with storeValue. Entries already in the document are reused for
+// nested structs so that unknown keys survive.
+func storeMap(v reflect.Value, existing interface{}, parent Container, field *field, prefix []string, leaf string, owner reflect.Type) (interface{}, error) {
+	existingEntries, ok := asMap(existing)
+	if !ok {
+		existingEntries = MapContainer(nil)
+	}
+	out := newMapLike(parent)
+	entries, _ := AsContainer(out)
+	keys := v.MapKeys()
+	formatted := make([]string, len(keys))
+	for i, key := range keys {
+		k, err := formatMapKey(key)
+		if err != nil {
+			return nil, ViewError{
+				Reason: fmt.Sprintf("cannot store key '%v' of '%s' %s: %s", key, v.Type(), location(prefix, leaf, owner), err),
+				Kind:   Unsupported,
+				Struct: owner,
+				Path:   fullPath(prefix, nil, leaf),
+				Actual: key.Type(),
+				Err:    err,
+			}
+		}
+		formatted[i] = k
+	}
+	// Store entries in key order so that errors are reported
+	// deterministically.
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return formatted[order[a]] < formatted[order[b]] })
+	for _, i := range order {
+		k := formatted[i]
+		// Copy the entry so that marshalers with pointer receivers apply.
+		elem := reflect.New(v.Type().Elem()).Elem()
+		elem.Set(v.MapIndex(keys[i]))
+		existingEntry, _ := existingEntries.Get(k)
+		entry, ok, err := storeValue(elem, existingEntry, parent, field, prefix, fmt.Sprintf("%s[%q]", leaf, k), owner)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			entries.Set(k, entry)
+		}
+	}
+	return out, nil
+}
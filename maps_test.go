@@ -0,0 +1,135 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"errors"
+
+	. "gopkg.in/check.v1"
+)
+
+type mapsView struct {
+	Labels  map[string]string      `views:"labels"`
+	Counts  map[string]int64       `views:"counts,convert"`
+	Leaves  map[string]leafView    `views:"leaves"`
+	Lists   map[string][]string    `views:"lists"`
+	Ports   map[int]string         `views:"ports"`
+	Tiers   map[labelKey]float64   `views:"tiers"`
+	Levels  map[level]bool         `views:"levels"`
+	Missing map[string]string      `views:"missing,optional"`
+	Raw     map[string]interface{} `views:"labels"`
+}
+
+func (s *ViewsSuite) TestFillMaps(c *C) {
+	data := s.getData([]byte(`
+	{
+		"labels": {"env": "prod", "tier": "web"},
+		"counts": {"a": 1.5, "b": 2},
+		"leaves": {"x": {"leaf": "one"}, "y": {"leaf": "two"}},
+		"lists": {"hosts": ["a", "b"]},
+		"ports": {"80": "http", "443": "https"},
+		"tiers": {"gold": 1, "silver": 0.5},
+		"levels": {"debug": false, "info": true}
+	}`))
+	out := mapsView{}
+	c.Assert(Fill(&out, "", data), IsNil)
+	c.Assert(out.Labels, DeepEquals, map[string]string{"env": "prod", "tier": "web"})
+	c.Assert(out.Counts, DeepEquals, map[string]int64{"a": 1, "b": 2})
+	c.Assert(out.Leaves, DeepEquals, map[string]leafView{"x": {Leaf: "one"}, "y": {Leaf: "two"}})
+	c.Assert(out.Lists, DeepEquals, map[string][]string{"hosts": {"a", "b"}})
+	c.Assert(out.Ports, DeepEquals, map[int]string{80: "http", 443: "https"})
+	c.Assert(out.Tiers, DeepEquals, map[labelKey]float64{"gold": 1, "silver": 0.5})
+	c.Assert(out.Levels, DeepEquals, map[level]bool{0: false, 1: true})
+	c.Assert(out.Missing, IsNil)
+	c.Assert(out.Raw, DeepEquals, data["labels"])
+
+	// YAML v2 decodes integer keys as ints.
+	yaml := map[interface{}]interface{}{
+		"ports": map[interface{}]interface{}{22: "ssh"},
+	}
+	var ports struct {
+		Ports map[uint16]string `views:"ports"`
+	}
+	c.Assert(Fill(&ports, "", yaml), IsNil)
+	c.Assert(ports.Ports, DeepEquals, map[uint16]string{22: "ssh"})
+
+	out.Ports[8080] = "alt"
+	out.Leaves["x"] = leafView{Leaf: "uno"}
+	out.Levels = map[level]bool{1: false}
+	c.Assert(Store(&out, "", data), IsNil)
+	c.Assert(data["ports"], DeepEquals, map[string]interface{}{"80": "http", "443": "https", "8080": "alt"})
+	c.Assert(data["leaves"], DeepEquals, map[string]interface{}{
+		"x": map[string]interface{}{"leaf": "uno"},
+		"y": map[string]interface{}{"leaf": "two"},
+	})
+	c.Assert(data["levels"], DeepEquals, map[string]interface{}{"info": false})
+	c.Assert(data["counts"], DeepEquals, map[string]interface{}{"a": float64(1), "b": float64(2)})
+}
+
+func (s *ViewsSuite) TestFillMapsBad(c *C) {
+	data := s.getData([]byte(`
+	{
+		"a": {
+			"labels": {"env": 1, "tier": "web"},
+			"ports": {"http": "80"},
+			"leaves": {"x": {"leaf": 2}},
+			"flat": "nope"
+		}
+	}`))
+
+	var labels struct {
+		Labels map[string]string `views:"labels"`
+	}
+	err := Fill(&labels, "a", data)
+	c.Assert(err, ErrorMatches, `view error - cannot assign or convert 'float64' to 'string' at path 'a.labels\["env"\]' .*`)
+	var viewErr ViewError
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Path, Equals, `a.labels["env"]`)
+	c.Assert(viewErr.JSONPointer(), Equals, "/a/labels/env")
+
+	var ports struct {
+		Ports map[int]string `views:"ports"`
+	}
+	err = Fill(&ports, "a", data)
+	c.Assert(err, ErrorMatches, `view error - cannot convert key 'http' to 'int' at path 'a.ports\["http"\]' .*`)
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Kind, Equals, ConversionFailed)
+
+	var leaves struct {
+		Leaves map[string]leafView `views:"leaves"`
+	}
+	c.Assert(Fill(&leaves, "a", data), ErrorMatches, `.* at path 'a.leaves\["x"\].leaf' .*`)
+
+	var flat struct {
+		Flat map[string]string `views:"flat"`
+	}
+	c.Assert(Fill(&flat, "a", data), ErrorMatches, `view error - cannot assign or convert 'string' to 'map\[string\]string' at path 'a.flat' .*`)
+
+	// FillAll keeps the entries that could be filled.
+	err = FillAll(&labels, "a", data)
+	c.Assert(err, ErrorMatches, `.*a.labels\["env"\].*`)
+	c.Assert(labels.Labels, DeepEquals, map[string]string{"tier": "web"})
+
+	c.Assert(ViewError{Path: `a.labels["x.y"][2]`}.Segments(), DeepEquals, []string{"a", `labels["x.y"][2]`})
+	c.Assert(ViewError{Path: `a.labels["x.y"][2]`}.JSONPointer(), Equals, "/a/labels/x.y/2")
+}
@@ -0,0 +1,461 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+type MutableFloat interface {
+	Set(float64)
+	Get() float64
+	GetChecked() (float64, bool)
+}
+
+type MutableString interface {
+	Set(string)
+	Get() string
+	GetChecked() (string, bool)
+}
+
+type MutableBool interface {
+	Set(bool)
+	Get() bool
+	GetChecked() (bool, bool)
+}
+
+// MutableInt views a number as an int64. Values are stored as float64 so the
+// container stays compatible with encoding/json.
+type MutableInt interface {
+	Set(int64)
+	Get() int64
+	GetChecked() (int64, bool)
+}
+
+type MutableMap interface {
+	Set(map[string]interface{})
+	Get() map[string]interface{}
+	GetChecked() (map[string]interface{}, bool)
+}
+
+type MutableSlice interface {
+	Set([]interface{})
+	Get() []interface{}
+	GetChecked() ([]interface{}, bool)
+}
+
+// MutableAny views a value of any type.
+type MutableAny interface {
+	Set(interface{})
+	Get() interface{}
+	GetChecked() (interface{}, bool)
+}
+
+var mutableFloatType = reflect.TypeOf((*MutableFloat)(nil)).Elem()
+var mutableStringType = reflect.TypeOf((*MutableString)(nil)).Elem()
+var mutableBoolType = reflect.TypeOf((*MutableBool)(nil)).Elem()
+var mutableIntType = reflect.TypeOf((*MutableInt)(nil)).Elem()
+var mutableMapType = reflect.TypeOf((*MutableMap)(nil)).Elem()
+var mutableSliceType = reflect.TypeOf((*MutableSlice)(nil)).Elem()
+var mutableAnyType = reflect.TypeOf((*MutableAny)(nil)).Elem()
+
+// A mutatorFactory binds a view interface such as MutableFloat to a key in a
+// container. It reports false if the value currently stored under the key has
+// the wrong type for the interface.
+type mutatorFactory func(container Container, key string, v interface{}) (reflect.Value, bool)
+
+// makeMutator returns the mutatorFactory for a view interface type, or nil if
+// typ is not a known view interface.
+func makeMutator(typ reflect.Type) mutatorFactory {
+	if typ.Kind() != reflect.Interface {
+		return nil
+	}
+	switch {
+	case typ.Implements(mutableFloatType):
+		return func(container Container, key string, v interface{}) (reflect.Value, bool) {
+			_, ok := toFloat64(v)
+			return reflect.ValueOf(floatMutator{container, key}), ok
+		}
+	case typ.Implements(mutableStringType):
+		return func(container Container, key string, v interface{}) (reflect.Value, bool) {
+			_, ok := v.(string)
+			return reflect.ValueOf(stringMutator{container, key}), ok
+		}
+	case typ.Implements(mutableBoolType):
+		return func(container Container, key string, v interface{}) (reflect.Value, bool) {
+			_, ok := v.(bool)
+			return reflect.ValueOf(boolMutator{container, key}), ok
+		}
+	case typ.Implements(mutableIntType):
+		return func(container Container, key string, v interface{}) (reflect.Value, bool) {
+			_, ok := toInt64(v)
+			return reflect.ValueOf(intMutator{container, key}), ok
+		}
+	case typ.Implements(mutableMapType):
+		return func(container Container, key string, v interface{}) (reflect.Value, bool) {
+			_, ok := asMap(v)
+			return reflect.ValueOf(mapMutator{container, key}), ok
+		}
+	case typ.Implements(mutableSliceType):
+		return func(container Container, key string, v interface{}) (reflect.Value, bool) {
+			_, ok := asSequence(v)
+			return reflect.ValueOf(sliceMutator{container, key}), ok
+		}
+	case typ.Implements(mutableAnyType):
+		return func(container Container, key string, v interface{}) (reflect.Value, bool) {
+			return reflect.ValueOf(anyMutator{container, key}), true
+		}
+	}
+	return nil
+}
+
+// toFloat64 returns v as a float64 if it holds a number of any type, since
+// decoders other than encoding/json produce integers as well.
+func toFloat64(v interface{}) (float64, bool) {
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	}
+	return 0, false
+}
+
+// toInt64 returns v as an int64 if it holds a number that is a whole number.
+func toInt64(v interface{}) (int64, bool) {
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if value.Uint() > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		n := value.Float()
+		if n != float64(int64(n)) {
+			return 0, false
+		}
+		return int64(n), true
+	}
+	return 0, false
+}
+
+// set stores value under key in container. Set on the view interfaces has no
+// error return, so a container refusing the value is a panic.
+func set(container Container, key string, value interface{}) {
+	if err := container.Set(key, value); err != nil {
+		panic(err)
+	}
+}
+
+type floatMutator struct {
+	container Container
+	key       string
+}
+
+func (m floatMutator) Get() float64 {
+	v, _ := m.GetChecked()
+	return v
+}
+func (m floatMutator) GetChecked() (float64, bool) {
+	v, _ := m.container.Get(m.key)
+	return toFloat64(v)
+}
+func (m floatMutator) Set(value float64) {
+	set(m.container, m.key, value)
+}
+
+type stringMutator struct {
+	container Container
+	key       string
+}
+
+func (m stringMutator) Get() string {
+	v, _ := m.GetChecked()
+	return v
+}
+func (m stringMutator) GetChecked() (string, bool) {
+	v, _ := m.container.Get(m.key)
+	value, ok := v.(string)
+	return value, ok
+}
+func (m stringMutator) Set(value string) {
+	set(m.container, m.key, value)
+}
+
+type boolMutator struct {
+	container Container
+	key       string
+}
+
+func (m boolMutator) Get() bool {
+	v, _ := m.GetChecked()
+	return v
+}
+func (m boolMutator) GetChecked() (bool, bool) {
+	v, _ := m.container.Get(m.key)
+	value, ok := v.(bool)
+	return value, ok
+}
+func (m boolMutator) Set(value bool) {
+	set(m.container, m.key, value)
+}
+
+type intMutator struct {
+	container Container
+	key       string
+}
+
+func (m intMutator) Get() int64 {
+	v, _ := m.GetChecked()
+	return v
+}
+func (m intMutator) GetChecked() (int64, bool) {
+	v, _ := m.container.Get(m.key)
+	return toInt64(v)
+}
+func (m intMutator) Set(value int64) {
+	set(m.container, m.key, float64(value))
+}
+
+type mapMutator struct {
+	container Container
+	key       string
+}
+
+func (m mapMutator) Get() map[string]interface{} {
+	v, _ := m.GetChecked()
+	return v
+}
+
+// GetChecked returns the map itself if the document holds a
+// map[string]interface{}, or otherwise a copy of it.
+func (m mapMutator) GetChecked() (map[string]interface{}, bool) {
+	v, _ := m.container.Get(m.key)
+	if value, ok := v.(map[string]interface{}); ok {
+		return value, true
+	}
+	c, ok := asMap(v)
+	if !ok {
+		return nil, false
+	}
+	value := make(map[string]interface{}, c.Len())
+	for _, k := range c.Keys() {
+		value[k], _ = c.Get(k)
+	}
+	return value, true
+}
+
+// Set stores value as a map of the kind the document is built from.
+func (m mapMutator) Set(value map[string]interface{}) {
+	if _, ok := newMapLike(m.container).(map[string]interface{}); ok || value == nil {
+		set(m.container, m.key, value)
+		return
+	}
+	out := newMapLike(m.container)
+	c, _ := AsContainer(out)
+	for k, v := range value {
+		c.Set(k, v)
+	}
+	set(m.container, m.key, out)
+}
+
+type sliceMutator struct {
+	container Container
+	key       string
+}
+
+func (m sliceMutator) Get() []interface{} {
+	v, _ := m.GetChecked()
+	return v
+}
+
+// GetChecked returns the slice itself if the document holds a
+// []interface{}, or otherwise a copy of the sequence.
+func (m sliceMutator) GetChecked() ([]interface{}, bool) {
+	v, _ := m.container.Get(m.key)
+	if value, ok := v.([]interface{}); ok {
+		return value, true
+	}
+	c, ok := asSequence(v)
+	if !ok {
+		return nil, false
+	}
+	value := make([]interface{}, c.Len())
+	for i := range value {
+		value[i], _ = c.Index(i)
+	}
+	return value, true
+}
+func (m sliceMutator) Set(value []interface{}) {
+	set(m.container, m.key, value)
+}
+
+type anyMutator struct {
+	container Container
+	key       string
+}
+
+func (m anyMutator) Get() interface{} {
+	v, _ := m.container.Get(m.key)
+	return v
+}
+func (m anyMutator) GetChecked() (interface{}, bool) {
+	return m.container.Get(m.key)
+}
+func (m anyMutator) Set(value interface{}) {
+	set(m.container, m.key, value)
+}
+
+// Mutable is a view of a single value of any type. Unlike the Mutable*
+// interfaces the value is converted on every access: Get and GetChecked
+// follow the same rules as a field tagged with the convert option, and Set
+// stores the value the same way Store does. Fields tagged optional are bound
+// even if the key or the maps on its path are missing, so that Exists
+// reports false until Set is called and creates them. Slice elements are
+// never created, so an optional field whose path runs through a missing
+// element is left unbound.
+type Mutable[T any] struct {
+	container Container
+	key       string
+	opts      *field // the options of the field the view was bound to
+}
+
+// mutableBinder is implemented by *Mutable[T] so that fillStruct can bind it
+// without knowing T.
+type mutableBinder interface {
+	bindView(container Container, key string, opts *field, check bool) bool
+	viewType() reflect.Type
+}
+
+// mutableValuer is implemented by Mutable[T] so that Store can write back the
+// raw value without knowing T.
+type mutableValuer interface {
+	viewValue() (interface{}, bool)
+	viewBound() bool
+}
+
+var mutableBinderType = reflect.TypeOf((*mutableBinder)(nil)).Elem()
+
+// convertField carries the options Mutable uses to convert values.
+var convertField = field{convert: true}
+
+// Get returns the value, or the zero value of T if it is missing or cannot be
+// converted to T.
+func (m Mutable[T]) Get() T {
+	v, _ := m.GetChecked()
+	return v
+}
+
+// GetChecked returns the value and reports whether it exists and could be
+// converted to T.
+func (m Mutable[T]) GetChecked() (T, bool) {
+	var out T
+	v, ok := m.viewValue()
+	if !ok {
+		return out, false
+	}
+	if err := (&filler{}).assignValue(reflect.ValueOf(&out).Elem(), v, m.convertOpts(), nil, m.key, nil); err != nil {
+		var zero T
+		return zero, false
+	}
+	return out, true
+}
+
+// Set stores value in the container. It panics if the Mutable was never
+// filled from a document, or if T cannot be stored in a document, such as a
+// channel or function type.
+func (m Mutable[T]) Set(value T) {
+	if m.container == nil {
+		panic(fmt.Sprintf("views: Set called on an unbound Mutable[%s]", reflect.TypeOf((*T)(nil)).Elem()))
+	}
+	existing, _ := m.viewValue()
+	v, present, err := storeValue(reflect.ValueOf(&value).Elem(), existing, m.container, m.convertOpts(), nil, m.key, nil)
+	if err != nil {
+		panic(err)
+	}
+	if present {
+		set(m.container, m.key, v)
+	} else {
+		m.Delete()
+	}
+}
+
+// Delete removes the value from the container. Slice elements are set to nil
+// instead.
+func (m Mutable[T]) Delete() {
+	if m.container == nil {
+		return
+	}
+	if err := m.container.Delete(m.key); err != nil {
+		panic(err)
+	}
+}
+
+// Exists reports whether the container holds a value for the view.
+func (m Mutable[T]) Exists() bool {
+	_, ok := m.viewValue()
+	return ok
+}
+
+// convertOpts returns the options Get and Set convert with: those of the
+// field the view was bound to, always with the convert option.
+func (m Mutable[T]) convertOpts() *field {
+	if m.opts == nil {
+		return &convertField
+	}
+	opts := *m.opts
+	opts.convert = true
+	return &opts
+}
+
+func (m *Mutable[T]) bindView(container Container, key string, opts *field, check bool) bool {
+	m.container, m.key, m.opts = container, key, opts
+	if !check {
+		return true
+	}
+	_, ok := m.GetChecked()
+	return ok
+}
+
+func (m *Mutable[T]) viewType() reflect.Type {
+	return typeFor[T]()
+}
+
+func (m Mutable[T]) viewBound() bool {
+	return m.container != nil
+}
+
+func (m Mutable[T]) viewValue() (interface{}, bool) {
+	if m.container == nil {
+		return nil, false
+	}
+	return m.container.Get(m.key)
+}
@@ -0,0 +1,283 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type mutableView struct {
+	Float  MutableFloat  `views:"float"`
+	String MutableString `views:"string"`
+	Bool   MutableBool   `views:"bool"`
+	Int    MutableInt    `views:"int"`
+	Map    MutableMap    `views:"map"`
+	Slice  MutableSlice  `views:"slice"`
+	Any    MutableAny    `views:"any"`
+}
+
+func (s *ViewsSuite) TestMutables(c *C) {
+	data := s.getData([]byte(`
+	{
+		"float": 1.5,
+		"string": "foo",
+		"bool": true,
+		"int": 42,
+		"map": { "a": 1 },
+		"slice": [1, "two"],
+		"any": null
+	}`))
+	view := mutableView{}
+	c.Assert(Fill(&view, "", data), IsNil)
+
+	c.Assert(view.Float.Get(), Equals, 1.5)
+	c.Assert(view.String.Get(), Equals, "foo")
+	c.Assert(view.Bool.Get(), Equals, true)
+	c.Assert(view.Int.Get(), Equals, int64(42))
+	c.Assert(view.Map.Get(), DeepEquals, map[string]interface{}{"a": float64(1)})
+	c.Assert(view.Slice.Get(), DeepEquals, []interface{}{float64(1), "two"})
+	v, ok := view.Any.GetChecked()
+	c.Assert(v, IsNil)
+	c.Assert(ok, Equals, true)
+
+	view.Bool.Set(false)
+	view.Int.Set(7)
+	view.Map.Set(map[string]interface{}{"b": "c"})
+	view.Slice.Set([]interface{}{})
+	view.Any.Set("anything")
+	c.Assert(data["bool"], Equals, false)
+	c.Assert(data["int"], Equals, float64(7))
+	c.Assert(view.Int.Get(), Equals, int64(7))
+	c.Assert(data["map"], DeepEquals, map[string]interface{}{"b": "c"})
+	c.Assert(data["slice"], DeepEquals, []interface{}{})
+	c.Assert(view.Any.Get(), Equals, "anything")
+
+	// Getters report whether the stored value still has the right type.
+	data["int"] = 1.5
+	_, ok = view.Int.GetChecked()
+	c.Assert(ok, Equals, false)
+	c.Assert(view.Int.Get(), Equals, int64(0))
+	data["bool"] = "true"
+	_, ok = view.Bool.GetChecked()
+	c.Assert(ok, Equals, false)
+	delete(data, "map")
+	c.Assert(view.Map.Get(), IsNil)
+	delete(data, "any")
+	_, ok = view.Any.GetChecked()
+	c.Assert(ok, Equals, false)
+}
+
+func (s *ViewsSuite) TestMutablesBad(c *C) {
+	data := s.getData([]byte(`
+	{
+		"float": 1.5,
+		"string": "foo",
+		"bool": true,
+		"int": 42,
+		"map": { "a": 1 },
+		"slice": [1, "two"],
+		"any": null
+	}`))
+	type badBool struct {
+		Bool MutableBool `views:"string"`
+	}
+	err := Fill(&badBool{}, "", data)
+	c.Assert(err, ErrorMatches, ".*cannot assign 'string' to 'views.MutableBool' at path '.string'.*")
+
+	type badInt struct {
+		Int MutableInt `views:"float"`
+	}
+	err = Fill(&badInt{}, "", data)
+	c.Assert(err, ErrorMatches, ".*cannot assign 'float64' to 'views.MutableInt' at path '.float'.*")
+
+	type badMap struct {
+		Map MutableMap `views:"slice"`
+	}
+	err = Fill(&badMap{}, "", data)
+	c.Assert(err, ErrorMatches, ".*cannot assign '\\[\\]interface \\{\\}' to 'views.MutableMap' at path '.slice'.*")
+
+	type badSlice struct {
+		Slice MutableSlice `views:"map"`
+	}
+	err = Fill(&badSlice{}, "", data)
+	c.Assert(err, ErrorMatches, ".*cannot assign 'map\\[string\\]interface \\{\\}' to 'views.MutableSlice' at path '.map'.*")
+}
+
+func (s *ViewsSuite) TestStoreMutables(c *C) {
+	data := s.getData([]byte(`{ "int": 1, "bool": false }`))
+	type view struct {
+		Int  MutableInt  `views:"int"`
+		Bool MutableBool `views:"bool"`
+	}
+	in := view{}
+	c.Assert(Fill(&in, "", data), IsNil)
+	in.Int.Set(3)
+
+	out := make(map[string]interface{})
+	c.Assert(Store(&in, "", out), IsNil)
+	c.Assert(out, DeepEquals, map[string]interface{}{"int": float64(3), "bool": false})
+
+	// Views that were never filled leave the document alone.
+	type unbound struct {
+		Int     MutableInt      `views:"int"`
+		Generic Mutable[bool]   `views:"bool"`
+		Deep    Mutable[string] `views:"a.b"`
+	}
+	c.Assert(Store(&unbound{}, "", out), IsNil)
+	c.Assert(out, DeepEquals, map[string]interface{}{"int": float64(3), "bool": false})
+}
+
+type genericView struct {
+	Int      Mutable[int64]         `views:"int"`
+	Float    Mutable[float64]       `views:"int"`
+	Typedef  Mutable[StringTypedef] `views:"string"`
+	Leaf     Mutable[leafView]      `views:"leaf"`
+	Tags     Mutable[[]string]      `views:"tags"`
+	Pointer  Mutable[*string]       `views:"null"`
+	Optional Mutable[string]        `views:"optional,optional"`
+}
+
+func (s *ViewsSuite) TestGenericMutable(c *C) {
+	data := s.getData([]byte(`
+	{
+		"int": 12.75,
+		"string": "foo",
+		"leaf": { "leaf": "green", "unknown": "kept" },
+		"tags": ["a", "b"],
+		"null": null
+	}`))
+	view := genericView{}
+	c.Assert(Fill(&view, "", data), IsNil)
+
+	c.Assert(view.Int.Get(), Equals, int64(12))
+	c.Assert(view.Float.Get(), Equals, 12.75)
+	c.Assert(view.Typedef.Get(), Equals, StringTypedef("foo"))
+	c.Assert(view.Leaf.Get(), Equals, leafView{"green"})
+	c.Assert(view.Tags.Get(), DeepEquals, []string{"a", "b"})
+	p, ok := view.Pointer.GetChecked()
+	c.Assert(p, IsNil)
+	c.Assert(ok, Equals, true)
+	c.Assert(view.Optional.Exists(), Equals, false)
+	_, ok = view.Optional.GetChecked()
+	c.Assert(ok, Equals, false)
+
+	view.Int.Set(3)
+	c.Assert(data["int"], Equals, float64(3))
+	view.Typedef.Set("bar")
+	c.Assert(data["string"], Equals, "bar")
+	view.Leaf.Set(leafView{"red"})
+	c.Assert(data["leaf"], DeepEquals, map[string]interface{}{"leaf": "red", "unknown": "kept"})
+	view.Tags.Set([]string{"c"})
+	c.Assert(data["tags"], DeepEquals, []interface{}{"c"})
+	name := "named"
+	view.Pointer.Set(&name)
+	c.Assert(data["null"], Equals, "named")
+	view.Pointer.Set(nil)
+	_, ok = data["null"]
+	c.Assert(ok, Equals, false)
+
+	view.Optional.Set("now")
+	c.Assert(view.Optional.Exists(), Equals, true)
+	c.Assert(data["optional"], Equals, "now")
+	view.Optional.Delete()
+	c.Assert(view.Optional.Exists(), Equals, false)
+	_, ok = data["optional"]
+	c.Assert(ok, Equals, false)
+
+	// Values that no longer convert are reported by GetChecked.
+	data["int"] = "three"
+	_, ok = view.Int.GetChecked()
+	c.Assert(ok, Equals, false)
+	c.Assert(view.Int.Get(), Equals, int64(0))
+
+	// Store writes back the raw value.
+	out := make(map[string]interface{})
+	c.Assert(Store(&view, "", out), IsNil)
+	c.Assert(out["int"], Equals, "three")
+	c.Assert(out["leaf"], DeepEquals, data["leaf"])
+	_, ok = out["optional"]
+	c.Assert(ok, Equals, false)
+}
+
+func (s *ViewsSuite) TestGenericMutableBad(c *C) {
+	data := s.getData([]byte(`{ "string": "foo", "list": [1] }`))
+
+	type badInt struct {
+		Int Mutable[int64] `views:"string"`
+	}
+	err := Fill(&badInt{}, "", data)
+	c.Assert(err, ErrorMatches, ".*cannot assign 'string' to 'views.Mutable\\[int64\\]' at path '.string'.*")
+
+	type missing struct {
+		Int Mutable[int64] `views:"missing"`
+	}
+	err = Fill(&missing{}, "", data)
+	c.Assert(err, ErrorMatches, ".*could not find .missing in container.*")
+
+	type element struct {
+		Int Mutable[int64] `views:"list[1]"`
+	}
+	err = Fill(&element{}, "", data)
+	c.Assert(err, ErrorMatches, ".*could not find .list\\[1\\] in container.*")
+}
+
+func (s *ViewsSuite) TestGenericMutableUnbound(c *C) {
+	var m Mutable[int64]
+	c.Assert(m.Exists(), Equals, false)
+	c.Assert(m.Get(), Equals, int64(0))
+	m.Delete()
+	c.Assert(func() { m.Set(1) }, PanicMatches, `views: Set called on an unbound Mutable\[int64\]`)
+}
+
+func (s *ViewsSuite) TestGenericMutableMissingParent(c *C) {
+	type view struct {
+		Note Mutable[string] `views:"x.y,optional"`
+	}
+	data := map[string]interface{}{}
+	out := view{}
+	c.Assert(Fill(&out, "", data), IsNil)
+	c.Assert(out.Note.Exists(), Equals, false)
+	c.Assert(data, HasLen, 0)
+	out.Note.Delete()
+	c.Assert(data, HasLen, 0)
+
+	out.Note.Set("hi")
+	c.Assert(data, DeepEquals, map[string]interface{}{"x": map[string]interface{}{"y": "hi"}})
+	c.Assert(out.Note.Get(), Equals, "hi")
+
+	// A null parent is replaced, and YAML documents get YAML maps.
+	yamlData := map[interface{}]interface{}{"x": nil}
+	c.Assert(Fill(&out, "", yamlData), IsNil)
+	c.Assert(out.Note.Exists(), Equals, false)
+	out.Note.Set("hi")
+	c.Assert(yamlData, DeepEquals, map[interface{}]interface{}{"x": map[interface{}]interface{}{"y": "hi"}})
+
+	// Slice elements are never created, so the view stays unbound.
+	type element struct {
+		Note Mutable[string] `views:"list[0].y,optional"`
+	}
+	elem := element{}
+	c.Assert(Fill(&elem, "", map[string]interface{}{}), IsNil)
+	c.Assert(elem.Note.Exists(), Equals, false)
+	c.Assert(func() { elem.Note.Set("hi") }, PanicMatches, `views: Set called on an unbound Mutable\[string\]`)
+}
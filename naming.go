@@ -0,0 +1,191 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"strings"
+	"unicode"
+)
+
+// A Naming is a strategy for matching Go field names to document keys.
+type Naming int
+
+const (
+	// ExactNaming matches keys exactly as written.
+	ExactNaming Naming = iota
+	// CaseInsensitive matches keys ignoring case, preferring an exact match.
+	CaseInsensitive
+	// SnakeCase matches UserID to user_id.
+	SnakeCase
+	// CamelCase matches UserID to userId.
+	CamelCase
+	// KebabCase matches UserID to user-id.
+	KebabCase
+)
+
+// WithNaming matches the fields of a single Fill that have no views tag,
+// which are otherwise looked up by their Go name, with naming n.
+func WithNaming(n Naming) Option {
+	return func(f *filler) {
+		f.naming = n
+	}
+}
+
+// WithKeyNaming is like WithNaming but applies n to every key looked up,
+// including the keys in tags and the base path.
+func WithKeyNaming(n Naming) Option {
+	return func(f *filler) {
+		f.naming, f.keyNaming = n, n
+	}
+}
+
+// Key returns the key name is written as under the naming.
+// CaseInsensitive and ExactNaming return name unchanged.
+func (n Naming) Key(name string) string {
+	var sep string
+	switch n {
+	case SnakeCase:
+		sep = "_"
+	case KebabCase:
+		sep = "-"
+	case CamelCase:
+		words := splitWords(name)
+		for i, word := range words {
+			word = strings.ToLower(word)
+			if i > 0 {
+				word = strings.ToUpper(word[:1]) + word[1:]
+			}
+			words[i] = word
+		}
+		return strings.Join(words, "")
+	default:
+		return name
+	}
+	return strings.ToLower(strings.Join(splitWords(name), sep))
+}
+
+// splitWords splits an identifier such as "HTTPServerID" or "user_id" into
+// its words, breaking at separators and changes of case. A run of capitals
+// is one word, except for a last capital followed by a lower case letter.
+func splitWords(s string) []string {
+	var words []string
+	runes := []rune(s)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '_' || r == '-' || r == ' ' || r == '.' {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(r) {
+			continue
+		}
+		prev := runes[i-1]
+		nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextLower {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// find returns the key of c matching key under the naming, or key if there
+// is none.
+func (n Naming) find(c Container, key string) string {
+	if n == ExactNaming {
+		return key
+	}
+	if n != CaseInsensitive {
+		if named := n.Key(key); named != key {
+			if _, ok := c.Get(named); ok {
+				return named
+			}
+		}
+		return key
+	}
+	if _, ok := c.Get(key); ok {
+		return key
+	}
+	for _, k := range c.Keys() {
+		if strings.EqualFold(k, key) {
+			return k
+		}
+	}
+	return key
+}
+
+// A namingContainer looks up keys in a map-like container using a Naming.
+// New keys are stored as the naming writes them.
+type namingContainer struct {
+	Container
+	naming Naming
+}
+
+func (c namingContainer) Get(key string) (interface{}, bool) {
+	return c.Container.Get(c.naming.find(c.Container, key))
+}
+
+func (c namingContainer) Set(key string, value interface{}) error {
+	if k := c.naming.find(c.Container, key); k != key {
+		return c.Container.Set(k, value)
+	}
+	if _, ok := c.Container.Get(key); ok {
+		return c.Container.Set(key, value)
+	}
+	return c.Container.Set(c.naming.Key(key), value)
+}
+
+func (c namingContainer) Delete(key string) error {
+	return c.Container.Delete(c.naming.find(c.Container, key))
+}
+
+// withNaming wraps c to look up keys with naming n. Sequences are returned
+// as is since their keys are indexes.
+func withNaming(c Container, n Naming) Container {
+	if n == ExactNaming {
+		return c
+	}
+	if isSequence(c) {
+		return c
+	}
+	if nc, ok := c.(namingContainer); ok {
+		c = nc.Container
+	}
+	return namingContainer{c, n}
+}
+
+// keys wraps c to look up keys with the naming that applies to every key of
+// the Fill call.
+func (f *filler) keys(c Container) Container {
+	if f == nil {
+		return c
+	}
+	return withNaming(c, f.keyNaming)
+}
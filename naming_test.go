@@ -0,0 +1,113 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type namingView struct {
+	UserID      string
+	DisplayName string
+	HTTPPort    int64 `views:",convert"`
+	Email       Mutable[string]
+	Tagged      string `views:"Tagged,optional"`
+}
+
+func (s *ViewsSuite) TestNamingKey(c *C) {
+	for _, t := range []struct {
+		name                string
+		snake, camel, kebab string
+	}{
+		{"UserID", "user_id", "userId", "user-id"},
+		{"HTTPServer", "http_server", "httpServer", "http-server"},
+		{"displayName", "display_name", "displayName", "display-name"},
+		{"user_id", "user_id", "userId", "user-id"},
+		{"Port8080", "port8080", "port8080", "port8080"},
+		{"X", "x", "x", "x"},
+	} {
+		c.Assert(SnakeCase.Key(t.name), Equals, t.snake, Commentf(t.name))
+		c.Assert(CamelCase.Key(t.name), Equals, t.camel, Commentf(t.name))
+		c.Assert(KebabCase.Key(t.name), Equals, t.kebab, Commentf(t.name))
+		c.Assert(ExactNaming.Key(t.name), Equals, t.name)
+		c.Assert(CaseInsensitive.Key(t.name), Equals, t.name)
+	}
+}
+
+func (s *ViewsSuite) TestFillNaming(c *C) {
+	for _, t := range []struct {
+		naming Naming
+		doc    string
+	}{
+		{SnakeCase, `{"user_id": "u1", "display_name": "Ann", "http_port": 80.0, "email": "a@b.c"}`},
+		{CamelCase, `{"userId": "u1", "displayName": "Ann", "httpPort": 80.0, "email": "a@b.c"}`},
+		{KebabCase, `{"user-id": "u1", "display-name": "Ann", "http-port": 80.0, "email": "a@b.c"}`},
+		{CaseInsensitive, `{"userid": "u1", "DISPLAYNAME": "Ann", "HttpPort": 80.0, "email": "a@b.c"}`},
+		{ExactNaming, `{"UserID": "u1", "DisplayName": "Ann", "HTTPPort": 80.0, "Email": "a@b.c"}`},
+	} {
+		data := s.getData([]byte(t.doc))
+		out := namingView{}
+		c.Assert(Fill(&out, "", data, WithNaming(t.naming)), IsNil, Commentf("%d", t.naming))
+		c.Assert(out.UserID, Equals, "u1")
+		c.Assert(out.DisplayName, Equals, "Ann")
+		c.Assert(out.HTTPPort, Equals, int64(80))
+		c.Assert(out.Email.Get(), Equals, "a@b.c")
+
+		// Mutables write back to the key they were found at.
+		out.Email.Set("x@y.z")
+		c.Assert(len(data), Equals, 4)
+	}
+
+	// Tagged fields are matched exactly unless the naming applies to every
+	// key.
+	data := s.getData([]byte(`{"user_id": "u1", "display_name": "Ann", "http_port": 80, "email": "a@b.c", "tagged": "t"}`))
+	out := namingView{}
+	c.Assert(Fill(&out, "", data, WithNaming(SnakeCase)), IsNil)
+	c.Assert(out.Tagged, Equals, "")
+	c.Assert(Fill(&out, "", data, WithKeyNaming(SnakeCase)), IsNil)
+	c.Assert(out.Tagged, Equals, "t")
+
+	// Without a naming the Go names do not match.
+	c.Assert(Fill(&namingView{}, "", data), ErrorMatches, "view error - could not find .UserID in container")
+}
+
+func (s *ViewsSuite) TestFillKeyNaming(c *C) {
+	data := s.getData([]byte(`
+	{
+		"Root": {
+			"Items": [{"Leaf": "one"}],
+			"Inner": {"Count": 2, "Name": "in", "Deeper": {"Leaf": "deep"}, "Weights": {"W": 0.5}}
+		}
+	}`))
+	var out struct {
+		Leaf  string    `views:"items[0].leaf"`
+		Inner innerView `views:"inner"`
+	}
+	c.Assert(Fill(&out, "root", data, WithKeyNaming(CaseInsensitive)), IsNil)
+	c.Assert(out.Leaf, Equals, "one")
+	c.Assert(out.Inner.Count, Equals, int64(2))
+	c.Assert(out.Inner.Deeper.Leaf, Equals, "deep")
+	c.Assert(out.Inner.Weighted, Equals, 0.5)
+
+	c.Assert(Fill(&out, "root", data), ErrorMatches, ".*no such key 'root'.*")
+}
@@ -0,0 +1,466 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// A Path locates a value in a document using the same syntax as views tags
+// and Fill base paths: dot-separated map keys, each optionally followed by
+// slice indexes, as in "a.b[2].c". Negative indexes count back from the end of
+// a slice. The empty path refers to the document itself.
+//
+// A Path can be passed to Fill and Store as the base path.
+type Path struct {
+	raw      string
+	segments []string
+	elems    []pathElem
+}
+
+// ParsePath parses s into a Path.
+func ParsePath(s string) (Path, error) {
+	if s == "" {
+		return Path{}, nil
+	}
+	segments := splitPath(s)
+	elems, err := parsePath(segments)
+	if err != nil {
+		return Path{}, err
+	}
+	for i, elem := range elems {
+		if elem.key == "" && (i > 0 || len(elem.indexes) == 0) {
+			return Path{}, ViewError{Reason: fmt.Sprintf("empty segment in path '%s'", s), Kind: InvalidPath, Path: s}
+		}
+	}
+	return Path{raw: s, segments: segments, elems: elems}, nil
+}
+
+// String returns the path as it was parsed.
+func (p Path) String() string {
+	return p.raw
+}
+
+// Get returns the value at the path in doc, or an error describing where
+// the path could not be followed.
+func (p Path) Get(doc interface{}) (interface{}, error) {
+	return p.get(doc, nil)
+}
+
+// get is Get with each container on the way passed through wrap, if it is
+// not nil.
+func (p Path) get(doc interface{}, wrap func(Container) Container) (interface{}, error) {
+	container, ok := AsContainer(doc)
+	if !ok {
+		return nil, notAContainer(doc)
+	}
+	if len(p.elems) == 0 {
+		return doc, nil
+	}
+	last := len(p.elems) - 1
+	parent, err := p.walk(container, last, wrap)
+	if err != nil {
+		return nil, err
+	}
+	value, perr := p.elems[last].lookup(parent)
+	if perr != nil {
+		return nil, elemError(nil, p.elems, last, perr)
+	}
+	return value, nil
+}
+
+// walk descends through the first n elements of the path, passing each
+// container on the way through wrap if it is not nil.
+func (p Path) walk(container Container, n int, wrap func(Container) Container) (Container, error) {
+	if wrap != nil {
+		container = wrap(container)
+	}
+	for i, elem := range p.elems[:n] {
+		value, perr := elem.lookup(container)
+		if perr == nil && value == nil {
+			perr = nullError(elem)
+		}
+		if perr != nil {
+			return nil, elemError(nil, p.elems, i, perr)
+		}
+		var ok bool
+		if container, ok = AsContainer(value); !ok {
+			return nil, elemError(nil, p.elems, i, &pathError{reason: fmt.Sprintf("expected map[string]interface{} not %s", typeName(value)), expected: mapType, actual: reflect.TypeOf(value)})
+		}
+		if wrap != nil {
+			container = wrap(container)
+		}
+	}
+	return container, nil
+}
+
+// Lookup is like Get but only reports whether the value exists.
+func (p Path) Lookup(doc interface{}) (interface{}, bool) {
+	value, err := p.Get(doc)
+	return value, err == nil
+}
+
+// Set stores value at the path in doc. Missing maps along the path are
+// created, and slices are created or grown with nil elements to reach a
+// non-negative index. A value that is in the way, such as a string where
+// the path expects a map, is an error rather than being replaced.
+func (p Path) Set(doc interface{}, value interface{}) error {
+	container, ok := AsContainer(doc)
+	if !ok {
+		return notAContainer(doc)
+	}
+	if len(p.elems) == 0 {
+		return ViewError{Reason: "cannot set the root of a document", Kind: Unsupported}
+	}
+	for i, elem := range p.elems {
+		last := i == len(p.elems)-1
+		var next Container
+		err := elem.update(container, func(existing interface{}) (interface{}, error) {
+			if last {
+				return value, nil
+			}
+			if existing == nil {
+				existing = newMapLike(container)
+			}
+			var ok bool
+			if next, ok = AsContainer(existing); !ok {
+				return nil, &pathError{reason: fmt.Sprintf("expected map[string]interface{} not %s", typeName(existing)), expected: mapType, actual: reflect.TypeOf(existing)}
+			}
+			return existing, nil
+		})
+		if perr, ok := err.(*pathError); ok {
+			return elemError(nil, p.elems, i, perr)
+		} else if err != nil {
+			return ViewError{
+				Reason: fmt.Sprintf("cannot set key '%s' at index %d in path '%s', %s", elem.raw, i, p.raw, err),
+				Kind:   Unsupported,
+				Path:   joinElems(nil, p.elems[:i+1]),
+				Err:    err,
+			}
+		}
+		container = next
+	}
+	return nil
+}
+
+// Delete removes the value at the path from doc. Slice elements are set to
+// nil rather than removed so that the indexes of later elements are kept.
+// Deleting a value that does not exist is not an error.
+func (p Path) Delete(doc interface{}) error {
+	container, ok := AsContainer(doc)
+	if !ok {
+		return notAContainer(doc)
+	}
+	if len(p.elems) == 0 {
+		return ViewError{Reason: "cannot delete the root of a document", Kind: Unsupported}
+	}
+	last := len(p.elems) - 1
+	parent, err := p.walk(container, last, nil)
+	if viewErr, ok := err.(ViewError); ok && viewErr.Kind == MissingKey {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if _, perr := p.elems[last].lookup(parent); perr != nil && perr.missing {
+		return nil
+	}
+	if err := p.elems[last].store(parent, nil, false); err != nil {
+		viewErr := err.(ViewError)
+		viewErr.Path = p.raw
+		return viewErr
+	}
+	return nil
+}
+
+// splitPath splits a dotted path into its segments. Dots inside the quoted
+// map keys of error paths, as in `labels["a.b"]`, do not split.
+func splitPath(s string) []string {
+	if !strings.Contains(s, `["`) {
+		return strings.Split(s, ".")
+	}
+	var segments []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			if quoted, err := strconv.QuotedPrefix(s[i:]); err == nil {
+				i += len(quoted) - 1
+			}
+		case '.':
+			segments = append(segments, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(segments, s[start:])
+}
+
+// A pathElem is one dot-separated segment of a view path: a map key
+// optionally followed by slice indexes, as in "e[-1]". Negative indexes count
+// back from the end of the slice.
+type pathElem struct {
+	raw     string
+	key     string
+	indexes []int
+}
+
+// A pathError describes why a pathElem could not be resolved. The caller
+// wraps it in a ViewError along with the surrounding path.
+type pathError struct {
+	reason   string
+	missing  bool
+	expected reflect.Type
+	actual   reflect.Type
+}
+
+func (e *pathError) Error() string {
+	return e.reason
+}
+
+var mapType = reflect.TypeOf(map[string]interface{}{})
+var sliceType = reflect.TypeOf([]interface{}{})
+
+func parsePathElem(s string) (pathElem, error) {
+	elem := pathElem{raw: s, key: s}
+	i := strings.IndexByte(s, '[')
+	if i == -1 {
+		if strings.IndexByte(s, ']') != -1 {
+			return pathElem{}, ViewError{Reason: fmt.Sprintf("malformed index in path segment '%s'", s), Kind: InvalidPath, Path: s}
+		}
+		return elem, nil
+	}
+	elem.key = s[:i]
+	for rest := s[i:]; rest != ""; {
+		end := strings.IndexByte(rest, ']')
+		if rest[0] != '[' || end == -1 {
+			return pathElem{}, ViewError{Reason: fmt.Sprintf("malformed index in path segment '%s'", s), Kind: InvalidPath, Path: s}
+		}
+		index, err := strconv.Atoi(rest[1:end])
+		if err != nil {
+			return pathElem{}, ViewError{Reason: fmt.Sprintf("malformed index '%s' in path segment '%s'", rest[1:end], s), Kind: InvalidPath, Path: s, Err: err}
+		}
+		elem.indexes = append(elem.indexes, index)
+		rest = rest[end+1:]
+	}
+	return elem, nil
+}
+
+func parsePath(path []string) ([]pathElem, error) {
+	elems := make([]pathElem, len(path))
+	for i, s := range path {
+		var err error
+		if elems[i], err = parsePathElem(s); err != nil {
+			return nil, err
+		}
+	}
+	return elems, nil
+}
+
+// hasIndexes reports whether any element of path indexes into a sequence.
+func hasIndexes(path []pathElem) bool {
+	for _, e := range path {
+		if len(e.indexes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup resolves the element against container.
+func (e pathElem) lookup(container Container) (interface{}, *pathError) {
+	parent, key, perr := e.parent(container)
+	if perr != nil {
+		return nil, perr
+	}
+	value, ok := parent.Get(key)
+	if !ok {
+		return nil, &pathError{reason: fmt.Sprintf("no such key '%s'", key), missing: true}
+	}
+	return value, nil
+}
+
+// parent resolves all but the last step of the element against container. It
+// returns the container directly holding the element's value and the key of
+// the value in it, so an element with indexes yields a sequence and the
+// decimal index of the value.
+func (e pathElem) parent(container Container) (Container, string, *pathError) {
+	if len(e.indexes) == 0 {
+		return container, e.key, nil
+	}
+	var value interface{} = container
+	if e.key != "" {
+		var ok bool
+		if value, ok = container.Get(e.key); !ok {
+			return nil, "", &pathError{reason: fmt.Sprintf("no such key '%s'", e.key), missing: true}
+		}
+	}
+	for i, index := range e.indexes {
+		if value == nil {
+			return nil, "", nullError(e)
+		}
+		items, ok := asSequence(value)
+		if !ok {
+			return nil, "", &pathError{reason: fmt.Sprintf("expected []interface{} not %s", typeName(value)), expected: sliceType, actual: reflect.TypeOf(value)}
+		}
+		if index < 0 {
+			index += items.Len()
+		}
+		if index < 0 || index >= items.Len() {
+			return nil, "", &pathError{reason: fmt.Sprintf("index %d out of range for key '%s'", index, e.key), missing: true}
+		}
+		if i == len(e.indexes)-1 {
+			return items, strconv.Itoa(index), nil
+		}
+		value, _ = items.Index(index)
+	}
+	panic("unreachable")
+}
+
+// store sets the element in container to value, or removes it if present is
+// false. Slices are never grown, and removed slice elements are set to nil.
+func (e pathElem) store(container Container, value interface{}, present bool) error {
+	parent, key, perr := e.parent(container)
+	if perr != nil {
+		kind := NotAContainer
+		if perr.missing {
+			kind = MissingKey
+		}
+		return ViewError{Reason: perr.reason, Kind: kind, Expected: perr.expected, Actual: perr.actual}
+	}
+	var err error
+	if present {
+		err = parent.Set(key, value)
+	} else {
+		err = parent.Delete(key)
+	}
+	if err != nil {
+		if viewErr, ok := err.(ViewError); ok {
+			return viewErr
+		}
+		return ViewError{Reason: err.Error(), Kind: Unsupported, Err: err}
+	}
+	return nil
+}
+
+// joinElems is joinPath for a parsed path.
+func joinElems(prefix []string, path []pathElem) string {
+	raw := make([]string, len(path))
+	for i, elem := range path {
+		raw[i] = elem.raw
+	}
+	return joinPath(prefix, raw)
+}
+
+// update replaces the value of the element in container with the result of
+// fn, which is passed the current value or nil if there is none. Slices along
+// the way are created or grown as needed, except for container itself.
+func (e pathElem) update(container Container, fn func(existing interface{}) (interface{}, error)) error {
+	if e.key != "" {
+		return updateIndexes(container, e.key, e.indexes, e.key, fn)
+	}
+	items, ok := asSequence(container)
+	if !ok {
+		return &pathError{reason: fmt.Sprintf("expected []interface{} not %s", typeName(container)), expected: sliceType, actual: reflect.TypeOf(container)}
+	}
+	index := e.indexes[0]
+	if index < 0 {
+		index += items.Len()
+	}
+	if index < 0 || index >= items.Len() {
+		return &pathError{reason: fmt.Sprintf("index %d out of range", index), missing: true}
+	}
+	return updateIndexes(items, strconv.Itoa(index), e.indexes[1:], e.key, fn)
+}
+
+// updateIndexes is update for the value under key in c followed by indexes.
+// The name is the key of the element, for error messages.
+func updateIndexes(c Container, key string, indexes []int, name string, fn func(existing interface{}) (interface{}, error)) error {
+	existing, _ := c.Get(key)
+	if len(indexes) == 0 {
+		v, err := fn(existing)
+		if err != nil {
+			return err
+		}
+		return c.Set(key, v)
+	}
+	var items Container = SliceContainer(nil)
+	if existing != nil {
+		var ok bool
+		if items, ok = asSequence(existing); !ok {
+			return &pathError{reason: fmt.Sprintf("expected []interface{} not %s", typeName(existing)), expected: sliceType, actual: reflect.TypeOf(existing)}
+		}
+	}
+	index := indexes[0]
+	if index < 0 {
+		index += items.Len()
+	}
+	if index < 0 {
+		return &pathError{reason: fmt.Sprintf("index %d out of range for key '%s'", index, name), missing: true}
+	}
+	if index >= items.Len() {
+		grown := make([]interface{}, index+1)
+		for i := 0; i < items.Len(); i++ {
+			grown[i], _ = items.Index(i)
+		}
+		if err := c.Set(key, grown); err != nil {
+			return err
+		}
+		items = SliceContainer(grown)
+	}
+	return updateIndexes(items, strconv.Itoa(index), indexes[1:], name, fn)
+}
+
+// nullError is the error for a null value found where a path continues. A
+// null is treated like a missing key.
+func nullError(e pathElem) *pathError {
+	return &pathError{reason: fmt.Sprintf("null value for key '%s'", e.raw), missing: true}
+}
+
+// typeName formats the type of v for error messages, naming null values.
+func typeName(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	return reflect.TypeOf(v).String()
+}
+
+// elemError wraps the failure to resolve path[i] in a ViewError. The prefix
+// is the path from the root of the document to where path starts.
+func elemError(prefix []string, path []pathElem, i int, perr *pathError) ViewError {
+	if perr.missing {
+		return ViewError{
+			Reason: fmt.Sprintf("%s at index %d in path '%s'", perr.reason, len(prefix)+i, joinElems(prefix, path)),
+			Kind:   MissingKey,
+			Path:   joinElems(prefix, path[:i+1]),
+		}
+	}
+	return ViewError{
+		Reason:   fmt.Sprintf("for key '%s' at index %d in path '%s', %s", path[i].key, len(prefix)+i, joinElems(prefix, path), perr.reason),
+		Kind:     NotAContainer,
+		Path:     joinElems(prefix, path[:i+1]),
+		Expected: perr.expected,
+		Actual:   perr.actual,
+	}
+}
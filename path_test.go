@@ -0,0 +1,152 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (s *ViewsSuite) TestParsePath(c *C) {
+	p, err := ParsePath("a.b[2].c")
+	c.Assert(err, IsNil)
+	c.Assert(p.String(), Equals, "a.b[2].c")
+	c.Assert(p.segments, DeepEquals, []string{"a", "b[2]", "c"})
+
+	p, err = ParsePath("")
+	c.Assert(err, IsNil)
+	c.Assert(p.String(), Equals, "")
+
+	_, err = ParsePath("a..b")
+	c.Assert(err, ErrorMatches, ".*empty segment in path 'a..b'.*")
+	_, err = ParsePath("a.[0]")
+	c.Assert(err, ErrorMatches, ".*empty segment in path 'a.\\[0\\]'.*")
+	_, err = ParsePath("a.b[x]")
+	c.Assert(err, ErrorMatches, ".*malformed index 'x' in path segment 'b\\[x\\]'.*")
+	c.Assert(err.(ViewError).Kind, Equals, InvalidPath)
+}
+
+func (s *ViewsSuite) TestPathGet(c *C) {
+	data := s.getData([]byte(`{ "a": { "b": [1, 2, { "c": "deep" }], "s": "str" } }`))
+
+	p, _ := ParsePath("a.b[2].c")
+	v, err := p.Get(data)
+	c.Assert(err, IsNil)
+	c.Assert(v, Equals, "deep")
+
+	p, _ = ParsePath("a.b[-3]")
+	v, ok := p.Lookup(data)
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, float64(1))
+
+	p, _ = ParsePath("")
+	v, err = p.Get(data)
+	c.Assert(err, IsNil)
+	c.Assert(v, DeepEquals, data)
+
+	p, _ = ParsePath("a.x")
+	_, err = p.Get(data)
+	c.Assert(err, ErrorMatches, ".*no such key 'x' at index 1 in path 'a.x'.*")
+	c.Assert(err.(ViewError).Kind, Equals, MissingKey)
+	_, ok = p.Lookup(data)
+	c.Assert(ok, Equals, false)
+
+	p, _ = ParsePath("a.b[5]")
+	_, err = p.Get(data)
+	c.Assert(err, ErrorMatches, ".*index 5 out of range for key 'b' at index 1 in path 'a.b\\[5\\]'.*")
+
+	p, _ = ParsePath("a.s.t")
+	_, err = p.Get(data)
+	c.Assert(err, ErrorMatches, ".*for key 's' at index 1 in path 'a.s.t', expected map\\[string\\]interface\\{\\} not string.*")
+	c.Assert(err.(ViewError).Kind, Equals, NotAContainer)
+}
+
+func (s *ViewsSuite) TestPathSet(c *C) {
+	data := map[string]interface{}{}
+
+	p, _ := ParsePath("a.b[2].c")
+	c.Assert(p.Set(data, "deep"), IsNil)
+	c.Assert(data, DeepEquals, map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": []interface{}{nil, nil, map[string]interface{}{"c": "deep"}},
+		},
+	})
+
+	// Existing slices are grown and existing values replaced.
+	p, _ = ParsePath("a.b[3][1]")
+	c.Assert(p.Set(data, 1.5), IsNil)
+	p, _ = ParsePath("a.b[-1]")
+	v, _ := p.Lookup(data)
+	c.Assert(v, DeepEquals, []interface{}{nil, 1.5})
+	p, _ = ParsePath("a.b[2].c")
+	c.Assert(p.Set(data, "replaced"), IsNil)
+	v, _ = p.Lookup(data)
+	c.Assert(v, Equals, "replaced")
+
+	p, _ = ParsePath("a.b[2].c.d")
+	err := p.Set(data, 1)
+	c.Assert(err, ErrorMatches, ".*for key 'c' at index 2 in path 'a.b\\[2\\].c.d', expected map\\[string\\]interface\\{\\} not string.*")
+	p, _ = ParsePath("a.b[-9]")
+	err = p.Set(data, 1)
+	c.Assert(err, ErrorMatches, ".*index -5 out of range for key 'b'.*")
+	p, _ = ParsePath("")
+	c.Assert(p.Set(data, 1), ErrorMatches, ".*cannot set the root of a document.*")
+
+	// Intermediate maps match the document.
+	yaml := map[interface{}]interface{}{}
+	p, _ = ParsePath("x.y")
+	c.Assert(p.Set(yaml, true), IsNil)
+	c.Assert(yaml, DeepEquals, map[interface{}]interface{}{"x": map[interface{}]interface{}{"y": true}})
+}
+
+func (s *ViewsSuite) TestPathDelete(c *C) {
+	data := s.getData([]byte(`{ "a": { "b": [1, 2], "c": "gone", "s": "str" } }`))
+
+	p, _ := ParsePath("a.c")
+	c.Assert(p.Delete(data), IsNil)
+	_, ok := p.Lookup(data)
+	c.Assert(ok, Equals, false)
+	c.Assert(p.Delete(data), IsNil)
+
+	p, _ = ParsePath("a.b[0]")
+	c.Assert(p.Delete(data), IsNil)
+	c.Assert(data["a"].(map[string]interface{})["b"], DeepEquals, []interface{}{nil, 2.0})
+
+	p, _ = ParsePath("x.y[3]")
+	c.Assert(p.Delete(data), IsNil)
+	p, _ = ParsePath("a.s.t")
+	c.Assert(p.Delete(data), ErrorMatches, ".*for key 's' at index 1 in path 'a.s.t', expected map\\[string\\]interface\\{\\} not string.*")
+}
+
+func (s *ViewsSuite) TestPathBase(c *C) {
+	data := s.getData([]byte(`{ "items": [{ "leaf": "first" }] }`))
+	p, _ := ParsePath("items[0]")
+	out := leafView{}
+	c.Assert(Fill(&out, p, data), IsNil)
+	c.Assert(out.Leaf, Equals, "first")
+
+	out.Leaf = "changed"
+	c.Assert(Store(&out, p, data), IsNil)
+	p, _ = ParsePath("items[0].leaf")
+	v, _ := p.Lookup(data)
+	c.Assert(v, Equals, "changed")
+}
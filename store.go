@@ -0,0 +1,252 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Store is the reverse of Fill. It writes every field of in back into out at
+// the path Fill would read it from, creating intermediate maps as needed.
+// Nested structs are merged into any map already present so that keys the
+// view does not know about are preserved. Nil pointers, slices, maps and
+// interfaces remove their key from the document, except view fields that
+// were never filled, which leave their key as it is. The document can be any
+// value AsContainer accepts.
+func Store(in interface{}, basePath interface{}, out interface{}) error {
+	switch basePath.(type) {
+	case string:
+		return storeToMap(in, splitPath(basePath.(string)), out)
+	case []string:
+		return storeToMap(in, basePath.([]string), out)
+	case Path:
+		return storeToMap(in, basePath.(Path).segments, out)
+	default:
+		panic(fmt.Sprintf("bad argument type to views.Store '%s'", reflect.TypeOf(basePath)))
+	}
+}
+
+func storeToMap(in interface{}, basePath []string, out interface{}) error {
+	container, ok := AsContainer(out)
+	if !ok {
+		return notAContainer(out)
+	}
+	if len(basePath) == 0 || basePath[0] == "" {
+		basePath = []string{}
+	} else {
+		elems, err := parsePath(basePath)
+		if err != nil {
+			return err
+		}
+		if container, err = makeContainer(nil, elems, container); err != nil {
+			return err
+		}
+	}
+
+	inValue := reflect.ValueOf(in)
+	if inValue.Kind() == reflect.Ptr {
+		inValue = inValue.Elem()
+	}
+	return storeStruct(inValue, basePath, container)
+}
+
+// storeStruct writes the fields of inValue into container. The prefix is the
+// path from the root of the document to container and is only used to build
+// error messages.
+func storeStruct(inValue reflect.Value, prefix []string, container Container) error {
+	inFields := cachedFields(inValue.Type())
+	for i := range inFields {
+		field := &inFields[i]
+		if field.err != nil {
+			return field.err
+		}
+		fieldInValue, err := inValue.FieldByIndexErr(field.index)
+		if err != nil {
+			// The field is promoted through a nil embedded pointer.
+			continue
+		}
+		if unboundView(fieldInValue, field) {
+			// The view has no value of its own, so the document keeps
+			// whatever it holds.
+			continue
+		}
+		fieldContainer, err := makeContainer(prefix, field.elems, container)
+		if err != nil {
+			return err
+		}
+
+		fieldPrefix := prefix
+		if len(field.path) > 0 {
+			fieldPrefix = append(append([]string{}, prefix...), field.path...)
+		}
+		existing, _ := field.leaf.lookup(fieldContainer)
+		v, present, err := storeValue(fieldInValue, existing, fieldContainer, field, fieldPrefix, field.name, inValue.Type())
+		if err != nil {
+			return err
+		}
+		if err = field.leaf.store(fieldContainer, v, present); err != nil {
+			viewErr := err.(ViewError)
+			viewErr.Reason = fmt.Sprintf("%s at path '%s.%s'", viewErr.Reason, joinPath(prefix, field.path), field.name)
+			viewErr.Struct = inValue.Type()
+			viewErr.Field = field.goName
+			viewErr.Path = fullPath(prefix, field.path, field.name)
+			return viewErr
+		}
+	}
+	return nil
+}
+
+// unboundView reports whether v is a view field that was never filled, such
+// as a nil MutableInt or a zero Mutable[T].
+func unboundView(v reflect.Value, field *field) bool {
+	if v.Kind() == reflect.Interface && field.mutatorFactory != nil {
+		return v.IsNil()
+	}
+	if m, ok := v.Interface().(mutableValuer); ok {
+		return !m.viewBound()
+	}
+	return false
+}
+
+// storeValue converts v into its document representation. The existing value
+// found in the document, if any, is reused for nested structs so that unknown
+// keys survive, and new maps are made like parent, the container the value
+// will be stored in. The field's options choose how times and durations are
+// formatted. It reports false if the value should be removed from the
+// document instead. The prefix, leaf and owner are only used to build error
+// messages.
+func storeValue(v reflect.Value, existing interface{}, parent Container, field *field, prefix []string, leaf string, owner reflect.Type) (interface{}, bool, error) {
+	if out, ok := storeBuiltin(v, field); ok {
+		return out, true, nil
+	}
+	if out, ok, err := marshal(v); err != nil {
+		return nil, false, ViewError{
+			Reason: fmt.Sprintf("cannot marshal '%s' %s: %s", v.Type(), location(prefix, leaf, owner), err),
+			Kind:   ConversionFailed,
+			Struct: owner,
+			Path:   fullPath(prefix, nil, leaf),
+			Actual: v.Type(),
+			Err:    err,
+		}
+	} else if ok {
+		return out, true, nil
+	}
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil, false, nil
+		}
+		switch m := v.Interface().(type) {
+		case MutableFloat:
+			value, ok := m.GetChecked()
+			return value, ok, nil
+		case MutableString:
+			value, ok := m.GetChecked()
+			return value, ok, nil
+		case MutableBool:
+			value, ok := m.GetChecked()
+			return value, ok, nil
+		case MutableInt:
+			value, ok := m.GetChecked()
+			return float64(value), ok, nil
+		case MutableMap:
+			value, ok := m.GetChecked()
+			return value, ok, nil
+		case MutableSlice:
+			value, ok := m.GetChecked()
+			return value, ok, nil
+		case MutableAny:
+			value, ok := m.GetChecked()
+			return value, ok, nil
+		}
+		return v.Interface(), true, nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, false, nil
+		}
+		return storeValue(v.Elem(), existing, parent, field, prefix, leaf, owner)
+	case reflect.Struct:
+		if m, ok := v.Interface().(mutableValuer); ok {
+			value, ok := m.viewValue()
+			return value, ok, nil
+		}
+		sub, ok := asMap(existing)
+		if !ok {
+			existing = newMapLike(parent)
+			sub, _ = AsContainer(existing)
+		}
+		if err := storeStruct(v, append(append([]string{}, prefix...), leaf), sub); err != nil {
+			return nil, false, err
+		}
+		return existing, true, nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, false, nil
+		}
+		if items, ok := v.Interface().([]interface{}); ok {
+			return items, true, nil
+		}
+		existingItems, ok := asSequence(existing)
+		if !ok {
+			existingItems = SliceContainer(nil)
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			existingItem, _ := existingItems.Index(i)
+			item, _, err := storeValue(v.Index(i), existingItem, parent, field, prefix, fmt.Sprintf("%s[%d]", leaf, i), owner)
+			if err != nil {
+				return nil, false, err
+			}
+			items[i] = item
+		}
+		return items, true, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, false, nil
+		}
+		if _, ok := AsContainer(v.Interface()); ok && v.Type().Elem().Kind() == reflect.Interface {
+			return v.Interface(), true, nil
+		}
+		out, err := storeMap(v, existing, parent, field, prefix, leaf, owner)
+		return out, err == nil, err
+	case reflect.Bool:
+		return v.Bool(), true, nil
+	case reflect.String:
+		return v.String(), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// Numbers are stored the way encoding/json decodes them.
+		return float64(v.Int()), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true, nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true, nil
+	}
+	return nil, false, ViewError{
+		Reason: fmt.Sprintf("cannot store '%s' %s", v.Type(), location(prefix, leaf, owner)),
+		Kind:   Unsupported,
+		Struct: owner,
+		Path:   fullPath(prefix, nil, leaf),
+		Actual: v.Type(),
+	}
+}
@@ -0,0 +1,143 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type storeView struct {
+	Count    int64           `views:"a.count,convert"`
+	Ratio    float64         `views:"a.ratio"`
+	Name     string          `views:"a.b.name"`
+	Typedef  StringTypedef   `views:"a.b.typedef,convert"`
+	Enabled  bool            `views:"enabled"`
+	Tags     []string        `views:"tags"`
+	Inner    leafView        `views:"a.inner"`
+	Elems    []sliceElemView `views:"elems"`
+	Optional *string         `views:"optional"`
+	Mutable  MutableFloat    `views:"a.ratio"`
+	Generic  []interface{}   `views:"generic"`
+	Ignored  string          `views:"-"`
+}
+
+func (s *ViewsSuite) TestStore(c *C) {
+	validData := []byte(`
+	{
+		"root": {
+			"a": {
+				"count": 3,
+				"ratio": 0.5,
+				"b": { "name": "foo", "typedef": "bar", "extra": 1 },
+				"inner": { "leaf": "green", "unknown": "kept" }
+			},
+			"enabled": true,
+			"tags": ["x"],
+			"elems": [{ "field1": "one", "size": 1, "unknown": "kept" }],
+			"optional": "present",
+			"generic": [1, "two"]
+		}
+	}`)
+	data := s.getData(validData)
+	view := storeView{}
+	c.Assert(Fill(&view, "root", data), IsNil)
+
+	view.Count = 4
+	view.Name = "baz"
+	view.Typedef = "qux"
+	view.Enabled = false
+	view.Tags = append(view.Tags, "y")
+	view.Inner.Leaf = "red"
+	view.Elems = append(view.Elems, sliceElemView{"two", 2})
+	view.Elems[0].Size = 10
+	view.Optional = nil
+	view.Ignored = "ignored"
+
+	err := Store(&view, "root", data)
+	c.Assert(err, IsNil)
+	root := data["root"].(map[string]interface{})
+	c.Assert(root["a"], DeepEquals, map[string]interface{}{
+		"count": float64(4),
+		"ratio": 0.5,
+		"b":     map[string]interface{}{"name": "baz", "typedef": "qux", "extra": float64(1)},
+		"inner": map[string]interface{}{"leaf": "red", "unknown": "kept"},
+	})
+	c.Assert(root["enabled"], Equals, false)
+	c.Assert(root["tags"], DeepEquals, []interface{}{"x", "y"})
+	c.Assert(root["elems"], DeepEquals, []interface{}{
+		map[string]interface{}{"field1": "one", "size": float64(10), "unknown": "kept"},
+		map[string]interface{}{"field1": "two", "size": float64(2)},
+	})
+	c.Assert(root["generic"], DeepEquals, []interface{}{float64(1), "two"})
+	_, ok := root["optional"]
+	c.Assert(ok, Equals, false)
+	_, ok = root["Ignored"]
+	c.Assert(ok, Equals, false)
+
+	// Storing and filling again round trips.
+	again := storeView{}
+	c.Assert(Fill(&again, "root", data), IsNil)
+	c.Assert(again.Name, Equals, "baz")
+	c.Assert(again.Elems, DeepEquals, view.Elems)
+	c.Assert(again.Mutable.Get(), Equals, 0.5)
+}
+
+func (s *ViewsSuite) TestStoreCreatesContainers(c *C) {
+	data := make(map[string]interface{})
+	view := storeView{Name: "foo", Tags: []string{"x"}}
+	err := Store(&view, "x.y", data)
+	c.Assert(err, IsNil)
+	root := data["x"].(map[string]interface{})["y"].(map[string]interface{})
+	c.Assert(root["a"].(map[string]interface{})["b"].(map[string]interface{})["name"], Equals, "foo")
+	c.Assert(root["a"].(map[string]interface{})["inner"], DeepEquals, map[string]interface{}{"leaf": ""})
+	c.Assert(root["tags"], DeepEquals, []interface{}{"x"})
+	c.Assert(root["elems"], IsNil)
+	_, ok := root["elems"]
+	c.Assert(ok, Equals, false)
+
+	data = map[string]interface{}{"x": "not a map"}
+	err = Store(&view, "x.y", data)
+	c.Assert(err, ErrorMatches, ".*for key 'x' at index 0 in path 'x.y', expected map\\[string\\]interface\\{\\} not string.*")
+}
+
+func (s *ViewsSuite) TestStoreIndexes(c *C) {
+	type view struct {
+		First string  `views:"items[0].name"`
+		Cell  float64 `views:"grid[1][-1]"`
+	}
+	data := s.getData([]byte(`
+	{
+		"items": [{ "name": "first" }],
+		"grid": [[1, 2], [3, 4]]
+	}`))
+	err := Store(&view{"changed", 5}, "", data)
+	c.Assert(err, IsNil)
+	c.Assert(data["items"], DeepEquals, []interface{}{map[string]interface{}{"name": "changed"}})
+	c.Assert(data["grid"], DeepEquals, []interface{}{[]interface{}{1.0, 2.0}, []interface{}{3.0, 5.0}})
+
+	type outOfRange struct {
+		Cell float64 `views:"grid[2][0]"`
+	}
+	err = Store(&outOfRange{}, "", data)
+	c.Assert(err, ErrorMatches, ".*index 2 out of range for key 'grid' at path '.grid\\[2\\]\\[0\\]'.*")
+}
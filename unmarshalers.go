@@ -0,0 +1,120 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// An unmarshaler records which decoding interface a type implements through
+// a pointer to it.
+type unmarshaler int
+
+const (
+	noUnmarshaler unmarshaler = iota
+	textUnmarshaler
+	jsonUnmarshaler
+	viewFiller
+)
+
+var viewFillerType = reflect.TypeOf((*ViewFiller)(nil)).Elem()
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// unmarshalerOf returns the decoding interface typ implements. ViewFiller is
+// preferred over the others, and like encoding/json, json.Unmarshaler is
+// preferred over encoding.TextUnmarshaler.
+func unmarshalerOf(typ reflect.Type) unmarshaler {
+	if typ.Kind() == reflect.Interface {
+		return noUnmarshaler
+	}
+	switch ptr := reflect.PointerTo(typ); {
+	case ptr.Implements(viewFillerType):
+		return viewFiller
+	case ptr.Implements(jsonUnmarshalerType):
+		return jsonUnmarshaler
+	case ptr.Implements(textUnmarshalerType):
+		return textUnmarshaler
+	}
+	return noUnmarshaler
+}
+
+// unmarshal fills dst from v using the decoding interface u. A
+// json.Unmarshaler is passed v re-encoded as JSON, and an
+// encoding.TextUnmarshaler is passed v if it is a string. It reports false if
+// u cannot decode v, so that the value can be assigned some other way.
+func unmarshal(dst reflect.Value, v interface{}, u unmarshaler) (bool, error) {
+	switch u {
+	case jsonUnmarshaler:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return true, err
+		}
+		return true, dst.Addr().Interface().(json.Unmarshaler).UnmarshalJSON(data)
+	case textUnmarshaler:
+		s, ok := v.(string)
+		if !ok {
+			return false, nil
+		}
+		return true, dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	return false, nil
+}
+
+// marshal is the reverse of unmarshal for Store, turning a value whose type
+// implements json.Marshaler or encoding.TextMarshaler into its document
+// representation. Only types unmarshal can read back are marshaled, and it
+// reports false for other values.
+func marshal(v reflect.Value) (interface{}, bool, error) {
+	if v.Kind() == reflect.Ptr || unmarshalerOf(v.Type()) == noUnmarshaler {
+		return nil, false, nil
+	}
+	m := v.Interface()
+	if !v.Type().Implements(jsonMarshalerType) && !v.Type().Implements(textMarshalerType) && v.CanAddr() {
+		m = v.Addr().Interface()
+	}
+	switch m := m.(type) {
+	case json.Marshaler:
+		data, err := m.MarshalJSON()
+		if err != nil {
+			return nil, false, err
+		}
+		var out interface{}
+		if err = json.Unmarshal(data, &out); err != nil {
+			return nil, false, fmt.Errorf("invalid JSON from MarshalJSON: %s", err)
+		}
+		return out, true, nil
+	case encoding.TextMarshaler:
+		text, err := m.MarshalText()
+		if err != nil {
+			return nil, false, err
+		}
+		return string(text), true, nil
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,150 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"reflect"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch strings.ToLower(string(text)) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	default:
+		return fmt.Errorf("unknown level '%s'", text)
+	}
+	return nil
+}
+
+func (l level) MarshalText() ([]byte, error) {
+	return []byte([]string{"debug", "info"}[l]), nil
+}
+
+// point accepts either [x, y] or {"x": x, "y": y}.
+type point struct {
+	X, Y float64
+}
+
+func (p *point) UnmarshalJSON(data []byte) error {
+	var pair []float64
+	if err := json.Unmarshal(data, &pair); err == nil && len(pair) == 2 {
+		p.X, p.Y = pair[0], pair[1]
+		return nil
+	}
+	var fields struct{ X, Y float64 }
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	p.X, p.Y = fields.X, fields.Y
+	return nil
+}
+
+func (p point) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]float64{p.X, p.Y})
+}
+
+// flex lowercases its keys, and could be assigned a decoded object as is.
+type flex map[string]interface{}
+
+func (f *flex) UnmarshalJSON(data []byte) error {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*f = flex{}
+	for k, v := range m {
+		(*f)[strings.ToLower(k)] = v
+	}
+	return nil
+}
+
+type unmarshalerView struct {
+	Addr   netip.Addr   `views:"addr"`
+	Addrs  []netip.Addr `views:"addrs"`
+	Level  level        `views:"level"`
+	Origin point        `views:"origin"`
+	Corner *point       `views:"corner"`
+	Flex   flex         `views:"flex"`
+}
+
+func (s *ViewsSuite) TestFillUnmarshalers(c *C) {
+	data := s.getData([]byte(`
+	{
+		"addr": "10.0.0.1",
+		"addrs": ["::1", "192.168.0.1"],
+		"level": "INFO",
+		"origin": [1, 2],
+		"corner": { "X": 3, "Y": 4 },
+		"flex": { "X": 1 }
+	}`))
+	out := unmarshalerView{}
+	c.Assert(Fill(&out, "", data), IsNil)
+	c.Assert(out.Addr, Equals, netip.MustParseAddr("10.0.0.1"))
+	c.Assert(out.Addrs, DeepEquals, []netip.Addr{netip.MustParseAddr("::1"), netip.MustParseAddr("192.168.0.1")})
+	c.Assert(out.Level, Equals, level(1))
+	c.Assert(out.Origin, Equals, point{1, 2})
+	c.Assert(*out.Corner, Equals, point{3, 4})
+	c.Assert(out.Flex, DeepEquals, flex{"x": 1.0})
+
+	fields := cachedFields(reflect.TypeOf(out))
+	c.Assert(fields[0].unmarshaler, Equals, textUnmarshaler)
+	c.Assert(fields[3].unmarshaler, Equals, jsonUnmarshaler)
+
+	// Store marshals the values back.
+	stored := make(map[string]interface{})
+	c.Assert(Store(&out, "", stored), IsNil)
+	c.Assert(stored["addr"], Equals, "10.0.0.1")
+	c.Assert(stored["addrs"], DeepEquals, []interface{}{"::1", "192.168.0.1"})
+	c.Assert(stored["level"], Equals, "info")
+	c.Assert(stored["origin"], DeepEquals, []interface{}{1.0, 2.0})
+	c.Assert(stored["corner"], DeepEquals, []interface{}{3.0, 4.0})
+}
+
+func (s *ViewsSuite) TestFillUnmarshalersBad(c *C) {
+	type addrView struct {
+		Addr netip.Addr `views:"addr"`
+	}
+	err := Fill(&addrView{}, "", map[string]interface{}{"addr": "nope"})
+	c.Assert(err, ErrorMatches, "view error - cannot unmarshal 'string' into 'netip.Addr' at path '.addr' in struct of type views.addrView: .*")
+	c.Assert(err.(ViewError).Kind, Equals, ConversionFailed)
+
+	// Text unmarshalers only read strings.
+	err = Fill(&addrView{}, "", map[string]interface{}{"addr": 10.0})
+	c.Assert(err, ErrorMatches, ".*cannot fill 'netip.Addr' from 'float64' at path '.addr'.*")
+
+	type levelView struct {
+		Level level `views:"level"`
+	}
+	err = Fill(&levelView{}, "", map[string]interface{}{"level": "trace"})
+	c.Assert(err, ErrorMatches, ".*unknown level 'trace'.*")
+}
@@ -0,0 +1,203 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A check is a validation tag option compiled into a field plan. It returns
+// the reason v violates the option, or "" if it does not.
+type check struct {
+	opt string
+	fn  func(v reflect.Value) string
+}
+
+// compileChecks builds the checks for the validation options in opts, which
+// are:
+//
+//	min=N, max=N  bounds on a number, or on the length of a string, slice or map
+//	len=N         the exact length of a string, slice or map
+//	pattern=RE    a regular expression a string must match
+//	oneof=a|b|c   the values a string or integer may take
+//	nonempty      the value must not be empty or zero
+//
+// Lengths of strings are counted in runes. Checks on a pointer field apply to
+// the value it points to.
+func compileChecks(opts tagOptions, typ reflect.Type) ([]check, error) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	var checks []check
+	for _, opt := range opts.List() {
+		name, value, _ := strings.Cut(opt, "=")
+		var fn func(v reflect.Value) string
+		switch name {
+		case "min", "max":
+			bound, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s bound '%s'", name, value)
+			}
+			what, measure := measureOf(typ)
+			if measure == nil {
+				return nil, fmt.Errorf("%s is not supported for '%s'", name, typ)
+			}
+			if name == "min" {
+				fn = func(v reflect.Value) string {
+					if m := measure(v); m < bound {
+						return fmt.Sprintf("%s %v is less than %v", what, m, bound)
+					}
+					return ""
+				}
+			} else {
+				fn = func(v reflect.Value) string {
+					if m := measure(v); m > bound {
+						return fmt.Sprintf("%s %v is greater than %v", what, m, bound)
+					}
+					return ""
+				}
+			}
+		case "len":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid length '%s'", value)
+			}
+			what, measure := measureOf(typ)
+			if what != "length" {
+				return nil, fmt.Errorf("len is not supported for '%s'", typ)
+			}
+			fn = func(v reflect.Value) string {
+				if m := measure(v); m != float64(n) {
+					return fmt.Sprintf("length %v is not %d", m, n)
+				}
+				return ""
+			}
+		case "pattern":
+			if typ.Kind() != reflect.String {
+				return nil, fmt.Errorf("pattern is not supported for '%s'", typ)
+			}
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return nil, err
+			}
+			fn = func(v reflect.Value) string {
+				if !re.MatchString(v.String()) {
+					return fmt.Sprintf("'%s' does not match '%s'", v.String(), value)
+				}
+				return ""
+			}
+		case "oneof":
+			switch typ.Kind() {
+			case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			default:
+				return nil, fmt.Errorf("oneof is not supported for '%s'", typ)
+			}
+			allowed := strings.Split(value, "|")
+			fn = func(v reflect.Value) string {
+				s := fmt.Sprint(v.Interface())
+				for _, a := range allowed {
+					if s == a {
+						return ""
+					}
+				}
+				return fmt.Sprintf("'%s' is not one of %s", s, strings.Join(allowed, ", "))
+			}
+		case "nonempty":
+			fn = func(v reflect.Value) string {
+				switch v.Kind() {
+				case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+					if v.Len() == 0 {
+						return "value is empty"
+					}
+				default:
+					if v.IsZero() {
+						return "value is empty"
+					}
+				}
+				return ""
+			}
+		default:
+			continue
+		}
+		checks = append(checks, check{opt: opt, fn: fn})
+	}
+	return checks, nil
+}
+
+// measureOf returns the quantity min and max bound for values of typ and how
+// to measure it, or a nil func if typ has no such quantity.
+func measureOf(typ reflect.Type) (string, func(v reflect.Value) float64) {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "value", func(v reflect.Value) float64 { return float64(v.Int()) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "value", func(v reflect.Value) float64 { return float64(v.Uint()) }
+	case reflect.Float32, reflect.Float64:
+		return "value", func(v reflect.Value) float64 { return v.Float() }
+	case reflect.String:
+		return "length", func(v reflect.Value) float64 { return float64(utf8.RuneCountInString(v.String())) }
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return "length", func(v reflect.Value) float64 { return float64(v.Len()) }
+	}
+	return "", nil
+}
+
+// validate runs the checks of field against the value filled into it. Nil
+// pointers only fail nonempty.
+func (f *filler) validate(outValue reflect.Value, field *field, prefix []string) error {
+	v := outValue.FieldByIndex(field.index)
+	for _, check := range field.checks {
+		value := v
+		for value.Kind() == reflect.Ptr && !value.IsNil() {
+			value = value.Elem()
+		}
+		var reason string
+		if value.Kind() == reflect.Ptr {
+			if check.opt == "nonempty" {
+				reason = "value is empty"
+			}
+		} else {
+			reason = check.fn(value)
+		}
+		if reason == "" {
+			continue
+		}
+		if err := f.fail(ViewError{
+			Reason:   fmt.Sprintf("validation '%s' failed for %s.%s: %s", check.opt, joinPath(prefix, field.path), field.name, reason),
+			Kind:     ValidationFailed,
+			Struct:   outValue.Type(),
+			Field:    field.goName,
+			Path:     fullPath(prefix, field.path, field.name),
+			Expected: field.typ,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
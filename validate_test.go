@@ -0,0 +1,137 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+type validatedView struct {
+	Port    int64    `views:"port,convert,min=1,max=65535"`
+	Ratio   float64  `views:"ratio,max=1"`
+	Name    string   `views:"name,min=2,max=5"`
+	Code    string   `views:"code,len=3,pattern=^[A-Z]{2,3}$"`
+	Level   string   `views:"level,oneof=debug|info|warn"`
+	Mode    int64    `views:"mode,convert,oneof=1|2"`
+	Tags    []string `views:"tags,nonempty"`
+	Owner   *string  `views:"owner,optional,nonempty"`
+	Retries int64    `views:"retries,optional,convert,min=1"`
+}
+
+func (s *ViewsSuite) TestFillValidation(c *C) {
+	data := s.getData([]byte(`
+	{
+		"port": 8080, "ratio": 0.5, "name": "héllo", "code": "ABC",
+		"level": "info", "mode": 2, "tags": ["a"], "owner": "me"
+	}`))
+	out := validatedView{}
+	c.Assert(Fill(&out, "", data), IsNil)
+	c.Assert(out.Port, Equals, int64(8080))
+	c.Assert(out.Retries, Equals, int64(0))
+
+	bad := s.getData([]byte(`
+	{
+		"port": 0, "ratio": 1.5, "name": "toolong", "code": "abc",
+		"level": "trace", "mode": 3, "tags": [], "owner": null, "retries": 0
+	}`))
+	err := FillAll(&validatedView{}, "", bad)
+	c.Assert(err, FitsTypeOf, ViewErrors{})
+	errs := err.(ViewErrors)
+	reasons := make([]string, len(errs))
+	for i, e := range errs {
+		c.Assert(e.Kind, Equals, ValidationFailed)
+		c.Assert(e.Struct, Equals, reflect.TypeOf(validatedView{}))
+		reasons[i] = e.Reason
+	}
+	c.Assert(reasons, DeepEquals, []string{
+		"validation 'min=1' failed for .port: value 0 is less than 1",
+		"validation 'max=1' failed for .ratio: value 1.5 is greater than 1",
+		"validation 'max=5' failed for .name: length 7 is greater than 5",
+		"validation 'pattern=^[A-Z]{2,3}$' failed for .code: 'abc' does not match '^[A-Z]{2,3}$'",
+		"validation 'oneof=debug|info|warn' failed for .level: 'trace' is not one of debug, info, warn",
+		"validation 'oneof=1|2' failed for .mode: '3' is not one of 1, 2",
+		"validation 'nonempty' failed for .tags: value is empty",
+		"validation 'nonempty' failed for .owner: value is empty",
+		"validation 'min=1' failed for .retries: value 0 is less than 1",
+	})
+	c.Assert(errs[0].Path, Equals, "port")
+
+	// A value that could not be filled is not validated.
+	bad = s.getData([]byte(`{ "sub": { "port": "x" } }`))
+	type nested struct {
+		Sub struct {
+			Port int64 `views:"port,min=1"`
+		} `views:"sub"`
+	}
+	err = FillAll(&nested{}, "", bad)
+	c.Assert(err, ErrorMatches, "view error - cannot assign or convert 'string' to 'int64' at path 'sub.port' in struct of type .*")
+}
+
+func (s *ViewsSuite) TestBadValidationOptions(c *C) {
+	data := map[string]interface{}{"value": true}
+
+	type badMin struct {
+		Value bool `views:"value,min=1"`
+	}
+	err := Fill(&badMin{}, "", data)
+	c.Assert(err, ErrorMatches, ".*invalid validation option on field Value of views.badMin: min is not supported for 'bool'.*")
+	c.Assert(err.(ViewError).Kind, Equals, InvalidTag)
+
+	type badPattern struct {
+		Value string `views:"value,pattern=(("`
+	}
+	err = Fill(&badPattern{}, "", data)
+	c.Assert(err, ErrorMatches, ".*invalid validation option on field Value .* missing closing \\).*")
+
+	type badLen struct {
+		Value int64 `views:"value,len=3"`
+	}
+	err = Fill(&badLen{}, "", data)
+	c.Assert(err, ErrorMatches, ".*len is not supported for 'int64'.*")
+}
+
+func (s *ViewsSuite) TestTagOptionsList(c *C) {
+	c.Assert(tagOptions("").List(), IsNil)
+	c.Assert(tagOptions("convert,min=1,max=2").List(), DeepEquals, []string{"convert", "min=1", "max=2"})
+	c.Assert(tagOptions("pattern=^a{1,3}$,optional").List(), DeepEquals, []string{"pattern=^a{1,3}$", "optional"})
+	c.Assert(tagOptions("optional,default=a,b").List(), DeepEquals, []string{"optional", "default=a,b"})
+	c.Assert(tagOptions("convert,min=1,omit").List(), DeepEquals, []string{"convert", "min=1", "omit"})
+	c.Assert(tagOptions(`pattern=^\{a,b$,omit`).List(), DeepEquals, []string{`pattern=^\{a`, "b$", "omit"})
+	c.Assert(tagOptions("pattern=^[(]+$,optional").List(), DeepEquals, []string{"pattern=^[(]+$", "optional"})
+	c.Assert(tagOptions("pattern=^[^]{]+$,optional").List(), DeepEquals, []string{"pattern=^[^]{]+$", "optional"})
+	c.Assert(tagOptions("pattern=^[a,b]$,optional").List(), DeepEquals, []string{"pattern=^[a,b]$", "optional"})
+
+	// Contains splits the same way as List.
+	c.Assert(tagOptions("pattern=^[(]+$,optional").Contains("optional"), Equals, true)
+	c.Assert(tagOptions("pattern=^(a,optional").Contains("optional"), Equals, false)
+	c.Assert(tagOptions("optional,default=a,omit").Contains("omit"), Equals, false)
+
+	type view struct {
+		Count int64 `views:"count,convert,min=1,omit"`
+	}
+	out := view{}
+	c.Assert(Fill(&out, "", map[string]interface{}{"count": 2.0}), IsNil)
+	c.Assert(out.Count, Equals, int64(2))
+}
@@ -0,0 +1,127 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// A ViewFiller fills itself from a document. Fill calls FillView for every
+// field whose type, or a pointer to it, implements ViewFiller, in place of
+// any other conversion. FillView is called with null values too, but not for
+// missing keys. Returning a ViewError, such as one from Value.Fill, reports
+// it as is; other errors are wrapped in a ViewError for the field.
+type ViewFiller interface {
+	FillView(v Value) error
+}
+
+// A Value is the part of a document a ViewFiller is filled from.
+type Value struct {
+	raw      interface{}
+	segments []string
+	f        *filler
+}
+
+// Raw returns the value as found in the document.
+func (v Value) Raw() interface{} {
+	return v.raw
+}
+
+// Path returns the path of the value from the root of the document.
+func (v Value) Path() string {
+	return strings.Join(v.segments, ".")
+}
+
+// Container returns the value as a Container, if it is one.
+func (v Value) Container() (Container, bool) {
+	return AsContainer(v.raw)
+}
+
+// Fill fills out from the value like Fill would, with the options of the
+// Fill call in progress. The path is relative to the value and given the
+// same way as the base path of Fill, and errors carry the full path from
+// the root of the document.
+func (v Value) Fill(out interface{}, path interface{}) error {
+	container, ok := v.Container()
+	if !ok {
+		return ViewError{
+			Reason: fmt.Sprintf("cannot fill from '%s' at path '%s', it is not a container", reflect.TypeOf(v.raw), v.Path()),
+			Kind:   NotAContainer,
+			Path:   v.Path(),
+			Actual: reflect.TypeOf(v.raw),
+		}
+	}
+	p, err := toPath(path, "Value.Fill")
+	if err != nil {
+		return err
+	}
+	prefix := v.segments
+	if len(p.elems) > 0 {
+		if container, err = v.f.walkContainer(prefix, p.elems, container); err != nil {
+			return err
+		}
+		prefix = append(append([]string{}, prefix...), p.segments...)
+	}
+
+	sub := *v.f
+	sub.errs = nil
+	outValue := reflect.ValueOf(out)
+	if outValue.Kind() == reflect.Ptr {
+		outValue = outValue.Elem()
+	}
+	if err := sub.fillStruct(outValue, prefix, container); err != nil {
+		return err
+	}
+	if len(sub.errs) > 0 {
+		return sub.errs
+	}
+	return nil
+}
+
+// fillView fills dst, whose type implements ViewFiller through a pointer,
+// from v. Errors are passed through fail.
+func (f *filler) fillView(dst reflect.Value, v interface{}, field *field, prefix []string, leaf string, owner reflect.Type) error {
+	segments := prefix
+	if leaf != "" {
+		segments = append(append([]string{}, prefix...), leaf)
+	}
+	err := dst.Addr().Interface().(ViewFiller).FillView(Value{raw: v, segments: segments, f: f})
+	switch err.(type) {
+	case nil:
+		return nil
+	case ViewError, ViewErrors:
+		return f.fail(err)
+	}
+	return f.fail(ViewError{
+		Reason:   fmt.Sprintf("cannot fill '%s' %s: %s", dst.Type(), location(prefix, leaf, owner), err),
+		Kind:     ConversionFailed,
+		Struct:   owner,
+		Field:    field.goName,
+		Path:     fullPath(prefix, nil, leaf),
+		Expected: dst.Type(),
+		Actual:   reflect.TypeOf(v),
+		Err:      err,
+	})
+}
@@ -0,0 +1,131 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2014 Justin Larrabee
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package views
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	. "gopkg.in/check.v1"
+)
+
+// shape fills either Circle or Rect depending on the kind key.
+type shape struct {
+	Path   string
+	Circle *struct {
+		Radius float64 `views:"radius"`
+	}
+	Rect *struct {
+		W float64 `views:"w"`
+		H float64 `views:"h"`
+	}
+}
+
+func (s *shape) FillView(v Value) error {
+	s.Path = v.Path()
+	kind, err := Get[string](v.Raw(), "kind")
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case "circle":
+		s.Circle = &struct {
+			Radius float64 `views:"radius"`
+		}{}
+		return v.Fill(s.Circle, "")
+	case "rect":
+		s.Rect = &struct {
+			W float64 `views:"w"`
+			H float64 `views:"h"`
+		}{}
+		return v.Fill(s.Rect, "size")
+	}
+	return fmt.Errorf("unknown shape '%s'", kind)
+}
+
+// nullable records whether it was filled from null.
+type nullable struct {
+	Null bool
+}
+
+func (n *nullable) FillView(v Value) error {
+	n.Null = v.Raw() == nil
+	return nil
+}
+
+type shapesView struct {
+	Main   shape    `views:"main"`
+	Others []shape  `views:"others"`
+	Opt    *shape   `views:"opt,optional"`
+	Marker nullable `views:"marker"`
+}
+
+func (s *ViewsSuite) TestFillViewFiller(c *C) {
+	data := s.getData([]byte(`
+	{
+		"doc": {
+			"main": { "kind": "circle", "radius": 2 },
+			"others": [{ "kind": "rect", "size": { "w": 3, "h": 4 } }],
+			"marker": null
+		}
+	}`))
+	out := shapesView{}
+	c.Assert(Fill(&out, "doc", data), IsNil)
+	c.Assert(out.Main.Path, Equals, "doc.main")
+	c.Assert(out.Main.Circle.Radius, Equals, 2.0)
+	c.Assert(out.Main.Rect, IsNil)
+	c.Assert(out.Others, HasLen, 1)
+	c.Assert(out.Others[0].Path, Equals, "doc.others[0]")
+	c.Assert(out.Others[0].Rect.W, Equals, 3.0)
+	c.Assert(out.Others[0].Rect.H, Equals, 4.0)
+	c.Assert(out.Opt, IsNil)
+	c.Assert(out.Marker.Null, Equals, true)
+
+	fields := cachedFields(reflect.TypeOf(out))
+	c.Assert(fields[0].unmarshaler, Equals, viewFiller)
+}
+
+func (s *ViewsSuite) TestFillViewFillerErrors(c *C) {
+	data := s.getData([]byte(`
+	{
+		"main": { "kind": "hexagon" },
+		"others": [{ "kind": "rect", "size": { "w": "wide", "h": "tall" } }],
+		"marker": 1
+	}`))
+	err := Fill(&shapesView{}, "", data)
+	c.Assert(err, ErrorMatches, "view error - cannot fill 'views.shape' at path '.main' in struct of type views.shapesView: unknown shape 'hexagon'")
+	c.Assert(err.(ViewError).Kind, Equals, ConversionFailed)
+	c.Assert(errors.Unwrap(err), ErrorMatches, "unknown shape 'hexagon'")
+
+	// Errors from the sub-Fill keep their full path and are all collected.
+	err = FillAll(&shapesView{}, "", data)
+	errs := err.(ViewErrors)
+	c.Assert(errs, HasLen, 3)
+	c.Assert(errs[1].Path, Equals, "others[0].size.w")
+	c.Assert(errs[1].Reason, Matches, "cannot assign or convert 'string' to 'float64' at path 'others\\[0\\].size.w' .*")
+	c.Assert(errs[2].Path, Equals, "others[0].size.h")
+
+	v := Value{raw: "text", segments: []string{"a"}, f: &filler{}}
+	c.Assert(v.Fill(&leafView{}, ""), ErrorMatches, ".*cannot fill from 'string' at path 'a', it is not a container.*")
+}
@@ -21,7 +21,8 @@
 // SOFTWARE.
 
 // Package views implements a type-safe method for accessing data stored in a
-// generic container such as map[string]interface{}
+// generic container such as map[string]interface{}. Any document can be
+// viewed as long as AsContainer understands it, see Container.
 
 package views
 
@@ -29,52 +30,92 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"sync"
 )
 
-type ViewError struct {
-	Reason string
+func Fill(out interface{}, basePath interface{}, in interface{}, opts ...Option) error {
+	return newFiller(opts).fill(out, basePath, in)
 }
 
-func (v ViewError) Error() string {
-	return fmt.Sprintf("view error - %s", v.Reason)
+// FillAll is like Fill but keeps going after a field fails to fill. If any
+// fields failed the returned error is a ViewErrors listing all of them.
+func FillAll(out interface{}, basePath interface{}, in interface{}, opts ...Option) error {
+	f := newFiller(opts)
+	f.all = true
+	if err := f.fill(out, basePath, in); err != nil {
+		return err
+	}
+	if len(f.errs) > 0 {
+		return f.errs
+	}
+	return nil
 }
 
-type MutableFloat interface {
-	Set(float64)
-	Get() float64
-	GetChecked() (float64, bool)
+// An Option changes how a single Fill or FillAll call fills a view.
+type Option func(*filler)
+
+// A filler holds the state of a single Fill call.
+type filler struct {
+	all        bool // keep going after a field fails, collecting errs
+	errs       ViewErrors
+	converters converters // in addition to the registered ones
+	naming     Naming     // for fields without a tag
+	keyNaming  Naming     // for every key
 }
 
-type MutableString interface {
-	Set(string)
-	Get() string
-	GetChecked() (string, bool)
+func newFiller(opts []Option) *filler {
+	f := &filler{}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
 }
 
-var mutableFloatType = reflect.TypeOf((*MutableFloat)(nil)).Elem()
-var mutableStringType = reflect.TypeOf((*MutableString)(nil)).Elem()
+// fail records err. It returns err if filling should stop.
+func (f *filler) fail(err error) error {
+	if f == nil || !f.all {
+		return err
+	}
+	switch viewErr := err.(type) {
+	case ViewError:
+		f.errs = append(f.errs, viewErr)
+		return nil
+	case ViewErrors:
+		f.errs = append(f.errs, viewErr...)
+		return nil
+	}
+	return err
+}
 
-func Fill(out interface{}, basePath interface{}, in map[string]interface{}) error {
+func (f *filler) fill(out interface{}, basePath interface{}, in interface{}) error {
 	switch basePath.(type) {
 	case string:
-		return fillFromMap(out, strings.Split(basePath.(string), "."), in)
+		return f.fillFromMap(out, splitPath(basePath.(string)), in)
 	case []string:
-		return fillFromMap(out, basePath.([]string), in)
+		return f.fillFromMap(out, basePath.([]string), in)
+	case Path:
+		return f.fillFromMap(out, basePath.(Path).segments, in)
 	default:
 		panic(fmt.Sprintf("bad argument type to views.Fill '%s'", reflect.TypeOf(basePath)))
 	}
 }
 
-func fillFromMap(out interface{}, basePath []string, in map[string]interface{}) error {
-	var container map[string]interface{}
-	var err error
+func fillFromMap(out interface{}, basePath []string, in interface{}) error {
+	return (&filler{}).fillFromMap(out, basePath, in)
+}
 
-	if len(basePath) == 0 || basePath[0] == "" {
-		container = in
-	} else {
-		container, err = getContainer(basePath, in)
+func (f *filler) fillFromMap(out interface{}, basePath []string, in interface{}) error {
+	root, ok := AsContainer(in)
+	if !ok {
+		return notAContainer(in)
 	}
-	if err != nil {
+
+	var container Container
+	var err error
+	if len(basePath) == 0 || basePath[0] == "" {
+		basePath = []string{}
+		container = root
+	} else if container, err = f.getContainer(basePath, root); err != nil {
 		return err
 	}
 
@@ -82,95 +123,511 @@ func fillFromMap(out interface{}, basePath []string, in map[string]interface{})
 	if outValue.Kind() == reflect.Ptr {
 		outValue = outValue.Elem()
 	}
-	outFields := getFields(outValue.Type())
-	for _, field := range outFields {
-		var (
-			fieldContainer map[string]interface{}
-			err            error
-			ok             bool
-			v              interface{}
-		)
-		if fieldContainer, err = getContainer(field.path, container); err != nil {
-			return err
-		}
-		if v, ok = fieldContainer[field.name]; !ok {
-			if field.optional {
-				continue
-			}
-			return ViewError{fmt.Sprintf("could not find %s.%s in container", strings.Join(field.path, "."), field.name)}
-		}
-
-		vValue := reflect.ValueOf(v)
-		vType := vValue.Type()
-		fieldOutValue := outValue.FieldByIndex(field.index)
-		fieldOutType := fieldOutValue.Type()
-
-		switch fieldOutValue.Kind() {
-		case reflect.Interface:
-			switch {
-			case fieldOutType.Implements(mutableFloatType):
-				if _, ok := v.(float64); ok {
-					fieldOutValue.Set(reflect.ValueOf(floatMapMutator{fieldContainer, field.name}))
-				} else {
-					return ViewError{fmt.Sprintf("cannot assign '%s' to '%s' at path '%s.%s' in struct of type %s", vType, fieldOutType, strings.Join(field.path, "."), field.name, outValue.Type())}
-				}
-			case fieldOutType.Implements(mutableStringType):
-				if _, ok := v.(string); ok {
-					fieldOutValue.Set(reflect.ValueOf(stringMapMutator{fieldContainer, field.name}))
-				} else {
-					return ViewError{fmt.Sprintf("cannot assign '%s' to '%s' at path '%s.%s' in struct of type %s", vType, fieldOutType, strings.Join(field.path, "."), field.name, outValue.Type())}
-				}
-			default:
-				return ViewError{fmt.Sprintf("could not set unknown view interface '%s' to at path '%s.%s'", fieldOutType, strings.Join(field.path, "."), field.name)}
+	return f.fillStruct(outValue, basePath, container)
+}
+
+// fillStruct fills the fields of outValue from container. The prefix is the
+// path from the root of the document to container and is only used to build
+// error messages.
+func (f *filler) fillStruct(outValue reflect.Value, prefix []string, container Container) error {
+	container = f.keys(container)
+	outFields := cachedFields(outValue.Type())
+	for i := range outFields {
+		field := &outFields[i]
+		if field.err != nil {
+			if err := f.fail(field.err); err != nil {
+				return err
 			}
 			continue
-		case reflect.Struct:
-			// todo, recurse
+		}
+		if len(field.aliases) > 0 {
+			field = f.chooseAlias(field, container)
+		}
+		fieldContainer, err := f.walkContainer(prefix, field.elems, container)
+		if viewErr, ok := err.(ViewError); ok && viewErr.Kind == MissingKey && len(field.aliases) > 0 {
+			err = field.missing(outValue.Type(), prefix)
+		}
+		if viewErr, ok := err.(ViewError); ok && viewErr.Kind == MissingKey && field.def.IsValid() {
+			fieldByIndex(outValue, field.index).Set(cloneValue(field.def))
+			if err = f.validate(outValue, field, prefix); err != nil {
+				return err
+			}
 			continue
-		case reflect.Slice:
-			// todo
+		} else if ok && viewErr.Kind == MissingKey && field.mutable && field.optional && !hasIndexes(field.elems) {
+			// An optional Mutable is bound even if its parent maps are
+			// missing, and Set creates them. Slice elements are never
+			// created, so a path through one leaves the view unbound.
+			fieldContainer = pathContainer{root: container, prefix: prefix, path: field.elems, wrap: f.keys}
+		} else if ok && viewErr.Kind == MissingKey && (field.optional || field.isPtr) {
+			// As for a missing leaf, pointers are left nil.
 			continue
-		case reflect.Ptr:
-			// todo
+		} else if err != nil {
+			viewErr := err.(ViewError)
+			viewErr.Struct, viewErr.Field = outValue.Type(), field.goName
+			if err = f.fail(viewErr); err != nil {
+				return err
+			}
 			continue
+		}
+		if err = f.fillField(outValue, field, prefix, fieldContainer); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// fillField fills a single field of outValue from the container the field's
+// path leads to. Errors are passed through fail.
+func (f *filler) fillField(outValue reflect.Value, field *field, prefix []string, fieldContainer Container) error {
+	if !field.tag && f.naming != ExactNaming {
+		fieldContainer = withNaming(fieldContainer, f.naming)
+	}
+	// Views are bound to the container directly holding the value, which is
+	// a slice if the leaf has indexes.
+	parent, key, perr := field.leaf.parent(fieldContainer)
+	var v interface{}
+	if perr == nil {
+		var ok bool
+		if v, ok = parent.Get(key); !ok {
+			perr = &pathError{reason: fmt.Sprintf("no such key '%s'", key), missing: true}
+		}
+	}
+	missing := perr != nil && perr.missing
+	if perr != nil && !missing {
+		return f.fail(ViewError{
+			Reason:   fmt.Sprintf("for key '%s' at path '%s.%s', %s", field.leaf.key, joinPath(prefix, field.path), field.name, perr.reason),
+			Kind:     NotAContainer,
+			Struct:   outValue.Type(),
+			Field:    field.goName,
+			Path:     fullPath(prefix, field.path, field.name),
+			Expected: perr.expected,
+			Actual:   perr.actual,
+		})
+	} else if (missing || v == nil) && field.def.IsValid() {
+		fieldByIndex(outValue, field.index).Set(cloneValue(field.def))
+		return f.validate(outValue, field, prefix)
+	} else if missing && !(field.mutable && field.optional && parent != nil) {
+		// Pointer fields model presence, so a missing key leaves them nil.
+		if field.optional || field.isPtr {
+			return nil
+		}
+		return f.fail(field.missing(outValue.Type(), prefix))
+	}
+
+	fieldOutValue := fieldByIndex(outValue, field.index)
+	fieldOutType := fieldOutValue.Type()
+
+	// A null value is treated as missing by optional fields, unless the
+	// field can hold nil itself.
+	null := !missing && v == nil
+	if null && field.optional && !field.nullable && !field.mutable && !canBeNil(fieldOutType) {
+		return nil
+	}
+
+	if field.mutable {
+		// An optional Mutable is bound even if the key is missing so that
+		// it can be Set later, unless a slice element is missing since
+		// slices never grow.
+		binder := fieldOutValue.Addr().Interface().(mutableBinder)
+		check := !missing && !(null && (field.optional || field.nullable))
+		if ok := binder.bindView(parent, key, field, check); !ok {
+			return f.fail(field.mismatch(outValue.Type(), fieldOutType, prefix, v))
+		}
+		return nil
+	}
+
+	// Empty interfaces hold the raw value and are assigned below.
+	if fieldOutValue.Kind() == reflect.Interface && fieldOutType.NumMethod() > 0 {
+		if field.mutatorFactory == nil {
+			return f.fail(ViewError{
+				Reason:   fmt.Sprintf("could not set unknown view interface '%s' to at path '%s.%s'", fieldOutType, joinPath(prefix, field.path), field.name),
+				Kind:     UnknownInterface,
+				Struct:   outValue.Type(),
+				Field:    field.goName,
+				Path:     fullPath(prefix, field.path, field.name),
+				Expected: fieldOutType,
+				Actual:   reflect.TypeOf(v),
+			})
+		}
+		if null && field.optional && !field.nullable {
+			return nil
+		}
+		mutator, ok := field.mutatorFactory(parent, key, v)
+		if !ok && !(null && field.nullable) {
+			return f.fail(field.mismatch(outValue.Type(), fieldOutType, prefix, v))
+		}
+		fieldOutValue.Set(mutator)
+		return nil
+	}
+
+	fieldPrefix := prefix
+	if len(field.path) > 0 {
+		fieldPrefix = append(append([]string{}, prefix...), field.path...)
+	}
+	failed := len(f.errs)
+	if err := f.assignValue(fieldOutValue, v, field, fieldPrefix, field.name, outValue.Type()); err != nil || len(f.errs) > failed {
+		return err
+	}
+	return f.validate(outValue, field, prefix)
+}
+
+// chooseAlias returns field moved to the first of its paths that holds a
+// value in container, or field itself if none do.
+func (f *filler) chooseAlias(field *field, container Container) *field {
+	if f.present(field.elems, field.leaf, container) {
+		return field
+	}
+	for _, a := range field.aliases {
+		if f.present(a.elems, a.leaf, container) {
+			moved := *field
+			moved.name, moved.path, moved.elems, moved.leaf = a.name, a.path, a.elems, a.leaf
+			moved.aliases = nil
+			return &moved
+		}
+	}
+	return field
+}
+
+// present reports whether the path made of elems and leaf leads to a value
+// in container.
+func (f *filler) present(elems []pathElem, leaf pathElem, container Container) bool {
+	c, err := f.walkContainer(nil, elems, container)
+	if err != nil {
+		return false
+	}
+	parent, key, perr := leaf.parent(c)
+	if perr != nil {
+		return false
+	}
+	_, ok := parent.Get(key)
+	return ok
+}
+
+// missing returns the error for a required field whose value is not in the
+// document, listing every path tried.
+func (field *field) missing(owner reflect.Type, prefix []string) ViewError {
+	paths := []string{fmt.Sprintf("%s.%s", joinPath(prefix, field.path), field.name)}
+	for _, a := range field.aliases {
+		paths = append(paths, fmt.Sprintf("%s.%s", joinPath(prefix, a.path), a.name))
+	}
+	reason := fmt.Sprintf("could not find %s in container", paths[0])
+	if len(paths) > 1 {
+		reason = fmt.Sprintf("could not find any of %s in container", strings.Join(paths, ", "))
+	}
+	return ViewError{
+		Reason:   reason,
+		Kind:     MissingKey,
+		Struct:   owner,
+		Field:    field.goName,
+		Path:     fullPath(prefix, field.path, field.name),
+		Expected: field.typ,
+	}
+}
+
+// mismatch returns the error for a view that cannot be bound to v: a
+// NullValue error if v is nil and a TypeMismatch error otherwise.
+func (field *field) mismatch(owner, typ reflect.Type, prefix []string, v interface{}) ViewError {
+	if v == nil {
+		return ViewError{
+			Reason:   fmt.Sprintf("cannot assign null to '%s' at path '%s.%s' in struct of type %s", typ, joinPath(prefix, field.path), field.name, owner),
+			Kind:     NullValue,
+			Struct:   owner,
+			Field:    field.goName,
+			Path:     fullPath(prefix, field.path, field.name),
+			Expected: typ,
+		}
+	}
+	return ViewError{
+		Reason:   fmt.Sprintf("cannot assign '%s' to '%s' at path '%s.%s' in struct of type %s", reflect.TypeOf(v), typ, joinPath(prefix, field.path), field.name, owner),
+		Kind:     TypeMismatch,
+		Struct:   owner,
+		Field:    field.goName,
+		Path:     fullPath(prefix, field.path, field.name),
+		Expected: typ,
+		Actual:   reflect.TypeOf(v),
+	}
+}
+
+// assignValue stores v into dst, recursing into structs, slices and maps as needed.
+// The prefix and leaf locate v in the document and owner is the struct type
+// the field belongs to; both are only used to build error messages. Errors
+// are passed through fail.
+func (f *filler) assignValue(dst reflect.Value, v interface{}, field *field, prefix []string, leaf string, owner reflect.Type) error {
+	dstType := dst.Type()
+	u := field.unmarshaler
+	if dstType != field.typ {
+		u = unmarshalerOf(dstType)
+	}
+	if u == viewFiller {
+		// A ViewFiller handles null values itself.
+		return f.fillView(dst, v, field, prefix, leaf, owner)
+	}
+	if v == nil {
+		if canBeNil(dstType) || field.nullable {
+			dst.Set(reflect.Zero(dstType))
+			return nil
+		}
+		return f.fail(ViewError{
+			Reason:   fmt.Sprintf("cannot assign null to '%s' %s", dstType, location(prefix, leaf, owner)),
+			Kind:     NullValue,
+			Struct:   owner,
+			Field:    field.goName,
+			Path:     fullPath(prefix, nil, leaf),
+			Expected: dstType,
+		})
+	}
+	if dst.Kind() == reflect.Ptr {
+		elem := reflect.New(dstType.Elem())
+		if err := f.assignValue(elem.Elem(), v, field, prefix, leaf, owner); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
 
-		default:
-			assignable := vType.AssignableTo(fieldOutType)
+	vValue := reflect.ValueOf(v)
+	vType := vValue.Type()
+
+	if !vType.AssignableTo(dstType) {
+		if conv := f.converter(vType, dstType); conv != nil {
+			return f.convertValue(dst, v, conv, field, prefix, leaf, owner)
+		}
+		if ok, err := fillBuiltin(dst, v, field); ok && err != nil {
+			return f.fail(ViewError{
+				Reason:   fmt.Sprintf("cannot convert '%s' to '%s' %s: %s", vType, dstType, location(prefix, leaf, owner), err),
+				Kind:     ConversionFailed,
+				Struct:   owner,
+				Field:    field.goName,
+				Path:     fullPath(prefix, nil, leaf),
+				Expected: dstType,
+				Actual:   vType,
+				Err:      err,
+			})
+		} else if ok {
+			return nil
+		}
+	}
+	// Types with an unmarshaler decode even values that could be assigned
+	// to them as is, such as a map to a named map type.
+	if ok, err := unmarshal(dst, v, u); ok && err != nil {
+		return f.fail(ViewError{
+			Reason:   fmt.Sprintf("cannot unmarshal '%s' into '%s' %s: %s", vType, dstType, location(prefix, leaf, owner), err),
+			Kind:     ConversionFailed,
+			Struct:   owner,
+			Field:    field.goName,
+			Path:     fullPath(prefix, nil, leaf),
+			Expected: dstType,
+			Actual:   vType,
+			Err:      err,
+		})
+	} else if ok {
+		return nil
+	}
 
-			if !fieldOutValue.CanSet() {
-				// This should be caught in getFields below
-				panic(ViewError{fmt.Sprintf("cannot set '%s' to at path '%s.%s'", fieldOutType, strings.Join(field.path, "."), field.name)})
-			} else if !assignable && field.convert && vType.ConvertibleTo(fieldOutType) {
-				// convert below
-			} else if !assignable {
-				return ViewError{fmt.Sprintf("cannot assign or convert '%s' to '%s' at path '%s.%s' in struct of type %s", vType, fieldOutType, strings.Join(field.path, "."), field.name, outValue.Type())}
+	switch dst.Kind() {
+	case reflect.Struct:
+		subContainer, ok := asMap(v)
+		if !ok {
+			return f.fail(ViewError{
+				Reason:   fmt.Sprintf("cannot fill '%s' from '%s' %s", dstType, vType, location(prefix, leaf, owner)),
+				Kind:     TypeMismatch,
+				Struct:   owner,
+				Field:    field.goName,
+				Path:     fullPath(prefix, nil, leaf),
+				Expected: dstType,
+				Actual:   vType,
+			})
+		}
+		return f.fillStruct(dst, append(append([]string{}, prefix...), leaf), subContainer)
+	case reflect.Slice:
+		if vType.AssignableTo(dstType) {
+			dst.Set(vValue)
+			return nil
+		}
+		items, ok := asSequence(v)
+		if !ok {
+			return f.fail(ViewError{
+				Reason:   fmt.Sprintf("cannot assign or convert '%s' to '%s' %s", vType, dstType, location(prefix, leaf, owner)),
+				Kind:     TypeMismatch,
+				Struct:   owner,
+				Field:    field.goName,
+				Path:     fullPath(prefix, nil, leaf),
+				Expected: dstType,
+				Actual:   vType,
+			})
+		}
+		out := reflect.MakeSlice(dstType, items.Len(), items.Len())
+		for i := 0; i < items.Len(); i++ {
+			item, _ := items.Index(i)
+			if err := f.assignValue(out.Index(i), item, field, prefix, fmt.Sprintf("%s[%d]", leaf, i), owner); err != nil {
+				return err
 			}
+		}
+		dst.Set(out)
+	case reflect.Map:
+		if vType.AssignableTo(dstType) {
+			dst.Set(vValue)
+			return nil
+		}
+		return f.fillMap(dst, v, field, prefix, leaf, owner)
+	default:
+		assignable := vType.AssignableTo(dstType)
 
-			if !assignable {
-				vValue = vValue.Convert(fieldOutType)
-			} else if field.isPtr {
-				vValue = reflect.ValueOf(&v)
+		if !dst.CanSet() {
+			// This should be caught in getFields below
+			panic(ViewError{Reason: fmt.Sprintf("cannot set '%s' to at path '%s.%s'", dstType, strings.Join(prefix, "."), leaf)})
+		} else if !assignable && field.convert && vType.ConvertibleTo(dstType) {
+			// convert below
+		} else if !assignable {
+			kind := TypeMismatch
+			if field.convert {
+				kind = ConversionFailed
 			}
-			fieldOutValue.Set(vValue)
+			return f.fail(ViewError{
+				Reason:   fmt.Sprintf("cannot assign or convert '%s' to '%s' %s", vType, dstType, location(prefix, leaf, owner)),
+				Kind:     kind,
+				Struct:   owner,
+				Field:    field.goName,
+				Path:     fullPath(prefix, nil, leaf),
+				Expected: dstType,
+				Actual:   vType,
+			})
 		}
-	}
 
+		if !assignable {
+			vValue = vValue.Convert(dstType)
+		}
+		dst.Set(vValue)
+	}
 	return nil
 }
 
-func getContainer(path []string, container map[string]interface{}) (map[string]interface{}, error) {
+// location renders where a value is for error messages, as in "at path
+// 'a.b' in struct of type T". The struct is left out if owner is nil.
+func location(prefix []string, leaf string, owner reflect.Type) string {
+	loc := fmt.Sprintf("at path '%s.%s'", strings.Join(prefix, "."), leaf)
+	if owner != nil {
+		loc += fmt.Sprintf(" in struct of type %s", owner)
+	}
+	return loc
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but allocates nil
+// embedded struct pointers on the way, as encoding/json does.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// canBeNil reports whether a value of type t can be set to nil to represent
+// a null in the document.
+func canBeNil(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// fullPath renders the dotted path from the root of the document to a value,
+// such as "a.b.e[3].field1".
+func fullPath(prefix []string, path []string, leaf string) string {
+	parts := make([]string, 0, len(prefix)+len(path)+1)
+	parts = append(append(append(parts, prefix...), path...), leaf)
+	return strings.Join(parts, ".")
+}
+
+// joinPath renders prefix followed by path as a dotted string for use in error
+// messages.
+func joinPath(prefix []string, path []string) string {
+	if len(prefix) == 0 {
+		return strings.Join(path, ".")
+	}
+	return strings.Join(append(append([]string{}, prefix...), path...), ".")
+}
+
+// notAContainer is the error for a document that AsContainer rejects.
+func notAContainer(in interface{}) error {
+	return ViewError{
+		Reason: fmt.Sprintf("cannot view '%s', it is not a container", typeName(in)),
+		Kind:   NotAContainer,
+		Actual: reflect.TypeOf(in),
+	}
+}
+
+func getContainer(path []string, container Container) (Container, error) {
+	return (*filler)(nil).getContainer(path, container)
+}
+
+func (f *filler) getContainer(path []string, container Container) (Container, error) {
+	elems, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return f.walkContainer(nil, elems, container)
+}
+
+// walkContainer descends through container along path, looking up keys with
+// the naming of the Fill call. The prefix is the path from the root of the
+// document to container and is only used to build error messages.
+func (f *filler) walkContainer(prefix []string, path []pathElem, container Container) (Container, error) {
+	return descend(prefix, path, container, false, f.keys)
+}
+
+// makeContainer is like walkContainer but creates any maps missing along path,
+// of the same kind as container. Slice elements are never created.
+func makeContainer(prefix []string, path []pathElem, container Container) (Container, error) {
+	return descend(prefix, path, container, true, nil)
+}
+
+// descend walks path from container. Each container on the way is passed
+// through wrap, if it is not nil.
+func descend(prefix []string, path []pathElem, container Container, create bool, wrap func(Container) Container) (Container, error) {
+	if wrap != nil {
+		container = wrap(container)
+	}
 	if len(path) == 0 {
 		return container, nil
 	}
 	outContainer := container
 	var ok bool
-	for i, key := range path {
-		var mapValue interface{}
-		if mapValue, ok = outContainer[key]; !ok {
-			return nil, ViewError{fmt.Sprintf("no such key '%s' at index %d in path '%s'", key, i, strings.Join(path, "."))}
+	for i, elem := range path {
+		value, perr := elem.lookup(outContainer)
+		if create && len(elem.indexes) == 0 && (perr != nil && perr.missing || perr == nil && value == nil) {
+			value = newMapLike(outContainer)
+			if err := outContainer.Set(elem.key, value); err != nil {
+				return nil, ViewError{
+					Reason: fmt.Sprintf("cannot create key '%s' at index %d in path '%s', %s", elem.key, len(prefix)+i, joinElems(prefix, path), err),
+					Kind:   Unsupported,
+					Path:   joinElems(prefix, path[:i+1]),
+					Err:    err,
+				}
+			}
+		} else if perr == nil && value == nil {
+			return nil, elemError(prefix, path, i, nullError(elem))
+		} else if perr != nil {
+			return nil, elemError(prefix, path, i, perr)
+		}
+		if outContainer, ok = AsContainer(value); !ok {
+			return nil, ViewError{
+				Reason:   fmt.Sprintf("for key '%s' at index %d in path '%s', expected map[string]interface{} not %s", elem.raw, len(prefix)+i, joinElems(prefix, path), typeName(value)),
+				Kind:     NotAContainer,
+				Path:     joinElems(prefix, path[:i+1]),
+				Expected: mapType,
+				Actual:   reflect.TypeOf(value),
+			}
 		}
-		if outContainer, ok = mapValue.(map[string]interface{}); !ok {
-			return nil, ViewError{fmt.Sprintf("for key '%s' at index %d in path '%s', expected map[string]interface{} not %s", key, i, strings.Join(path, "."), reflect.TypeOf(mapValue))}
+		if wrap != nil {
+			outContainer = wrap(outContainer)
 		}
 	}
 	return outContainer, nil
@@ -178,8 +635,14 @@ func getContainer(path []string, container map[string]interface{}) (map[string]i
 
 // A field represents a single field found in a struct.
 type field struct {
-	name string
-	path []string
+	name   string
+	goName string // name of the struct field
+	path   []string
+	elems  []pathElem
+	leaf   pathElem
+	err    error // set if the tag could not be parsed
+
+	aliases []alias // further paths tried in order if the value is missing
 
 	tag            bool
 	index          []int
@@ -187,75 +650,110 @@ type field struct {
 	isPtr          bool
 	convert        bool
 	optional       bool
-	mutatorFactory interface{} // should be castable to a specific mutator based on typ and the container type
+	nullable       bool
+	def            reflect.Value // from the default option, invalid if unset
+	checks         []check
+	format         string // how times and durations are represented
+	layout         string // time layout for times stored as strings
+	unmarshaler    unmarshaler
+	mutable        bool // a Mutable[T], bound through mutableBinder
+	mutatorFactory mutatorFactory
 }
 
-type floatMapMutator struct {
-	container map[string]interface{}
-	key       string
+// A fieldPlan is the cached result of typeFields.
+type fieldPlan struct {
+	fields    []field
+	conflicts ViewErrors
 }
 
-func (m floatMapMutator) Get() float64 {
-	if v, ok := m.container[m.key].(float64); ok {
-		return v
+var fieldCache sync.Map // map[reflect.Type]*fieldPlan
+
+// cachedPlan is like typeFields but only builds the field plan for a type
+// once. The returned plan is shared and must not be modified.
+func cachedPlan(t reflect.Type) *fieldPlan {
+	if p, ok := fieldCache.Load(t); ok {
+		return p.(*fieldPlan)
 	}
-	return 0.0
-}
-func (m floatMapMutator) GetChecked() (float64, bool) {
-	v, ok := m.container[m.key].(float64)
-	return v, ok
-}
-func (m floatMapMutator) Set(value float64) {
-	m.container[m.key] = value
+	fields, conflicts := typeFields(t)
+	p, _ := fieldCache.LoadOrStore(t, &fieldPlan{fields, conflicts})
+	return p.(*fieldPlan)
 }
 
-type stringMapMutator struct {
-	container map[string]interface{}
-	key       string
+// cachedFields is like getFields but only builds the field plan for a type
+// once. The returned slice is shared and must not be modified.
+func cachedFields(t reflect.Type) []field {
+	return cachedPlan(t).fields
 }
 
-func (m stringMapMutator) Get() string {
-	if v, ok := m.container[m.key].(string); ok {
-		return v
+// CheckView reports the fields of the view struct type of view, a struct or
+// a pointer to one, that Fill and Store leave out because they are
+// ambiguous. The returned error is a ViewErrors of kind DuplicatePath, or
+// nil if there are none.
+func CheckView(view interface{}) error {
+	t := reflect.TypeOf(view)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
 	}
-	return ""
-}
-func (m stringMapMutator) GetChecked() (string, bool) {
-	v, ok := m.container[m.key].(string)
-	return v, ok
-}
-func (m stringMapMutator) Set(value string) {
-	m.container[m.key] = value
+	if t == nil || t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("bad argument type to views.CheckView '%s'", reflect.TypeOf(view)))
+	}
+	if conflicts := cachedPlan(t).conflicts; len(conflicts) > 0 {
+		return conflicts
+	}
+	return nil
 }
 
-// This is based off of encoding/json
+// getFields returns the field plan of struct type t.
 func getFields(t reflect.Type) []field {
+	fields, _ := typeFields(t)
+	return fields
+}
+
+// typeFields returns the field plan of struct type t along with the fields
+// left out because they are ambiguous. This is based off of encoding/json.
+func typeFields(t reflect.Type) ([]field, ViewErrors) {
 	// Anonymous fields to explore at the current level and the next.
 	current := []field{}
 	next := []field{{typ: t}}
 
-	// Count of queued names for current level and the next.
-	count := map[reflect.Type]int{}
-	nextCount := map[reflect.Type]int{}
+	// Count of queued names for current level and the next. An embedded
+	// type is keyed by its path too, since the same type can be inlined
+	// under different prefixes.
+	count := map[embedKey]int{}
+	nextCount := map[embedKey]int{}
 
 	// Types already visited and fields collected
-	visited := map[reflect.Type]bool{}
+	visited := map[embedKey]bool{}
 	var fields []field
 
 	for len(next) > 0 {
 		current, next = next, current[:0]
-		count, nextCount = nextCount, map[reflect.Type]int{}
+		count, nextCount = nextCount, map[embedKey]int{}
 
 		for _, typeField := range current {
-			if visited[typeField.typ] {
+			key := embedKey{typeField.typ, strings.Join(typeField.path, ".")}
+			if visited[key] {
 				continue
 			}
-			visited[typeField.typ] = true
+			visited[key] = true
 
 			// Scan f.typ for fields to include.
 			for i := 0; i < typeField.typ.NumField(); i++ {
 				structField := typeField.typ.Field(i)
-				if structField.PkgPath != "" { // unexported
+				if structField.Anonymous {
+					t := structField.Type
+					if t.Kind() == reflect.Ptr {
+						t = t.Elem()
+					}
+					if structField.PkgPath != "" && (t.Kind() != reflect.Struct || structField.Type.Kind() == reflect.Ptr) {
+						// Ignore embedded fields of unexported non-struct
+						// types, and pointers to unexported struct types
+						// since they cannot be allocated.
+						continue
+					}
+					// Do not ignore embedded fields of unexported struct
+					// types since they may have exported fields.
+				} else if structField.PkgPath != "" { // unexported
 					continue
 				}
 
@@ -268,6 +766,7 @@ func getFields(t reflect.Type) []field {
 				if !isValidTag(name) {
 					name = ""
 				}
+				alternatives := aliasesOf(tag)
 
 				index := make([]int, len(typeField.index)+1)
 				copy(index, typeField.index)
@@ -281,25 +780,127 @@ func getFields(t reflect.Type) []field {
 					isPtr = true
 				}
 
+				// Fields of embedded structs are relative to the path the
+				// struct is embedded at.
+				path = append(append([]string{}, typeField.path...), path...)
+				// A struct embedding itself under a path, directly or not,
+				// would lead to ever longer paths if inlined, so it is
+				// filled as a named field instead.
+				inline := structField.Anonymous && structFieldType.Kind() == reflect.Struct && isInline(structFieldType, name, opts) &&
+					!(name != "" && onChain(t, index, structFieldType))
+
 				// Record found field and index sequence.
-				if name != "" || !structField.Anonymous || structFieldType.Kind() != reflect.Struct {
+				if !inline {
+					if structField.PkgPath != "" {
+						continue
+					}
 					tagged := name != ""
 					if name == "" {
 						name = structField.Name
-						path = []string{}
+					}
+					var leaf pathElem
+					var aliases []alias
+					elems, err := parsePath(path)
+					if err == nil {
+						leaf, err = parsePathElem(name)
+					}
+					for _, alternative := range alternatives {
+						if err != nil {
+							break
+						}
+						var a alias
+						if a, err = parseAlias(alternative, typeField.path); err == nil {
+							aliases = append(aliases, a)
+						}
+					}
+					if err != nil {
+						err = ViewError{
+							Reason: fmt.Sprintf("invalid views tag on field %s of %s: %s", structField.Name, typeField.typ, err.(ViewError).Reason),
+							Kind:   InvalidTag,
+							Struct: typeField.typ,
+							Field:  structField.Name,
+							Path:   tag,
+							Err:    err,
+						}
+					}
+					mutatorFactory := makeMutator(structFieldType)
+					mutable := reflect.PointerTo(structField.Type).Implements(mutableBinderType)
+					format, _ := opts.Value("format")
+					layout, _ := opts.Value("layout")
+					if err == nil {
+						if err = checkFormat(formatTarget(structField.Type), format, layout); err != nil {
+							err = ViewError{
+								Reason: fmt.Sprintf("invalid format on field %s of %s: %s", structField.Name, typeField.typ, err),
+								Kind:   InvalidTag,
+								Struct: typeField.typ,
+								Field:  structField.Name,
+								Path:   tag,
+								Err:    err,
+							}
+						}
+					}
+					def, hasDefault := opts.Default()
+					var defValue reflect.Value
+					if hasDefault && err == nil {
+						if mutable || mutatorFactory != nil {
+							err = fmt.Errorf("defaults are not supported for view fields")
+						} else {
+							defValue, err = parseDefault(def, structField.Type, &field{format: format, layout: layout})
+						}
+						if err != nil {
+							reason := err.Error()
+							if viewErr, ok := err.(ViewError); ok {
+								reason = viewErr.Reason
+							}
+							err = ViewError{
+								Reason: fmt.Sprintf("invalid default '%s' on field %s of %s: %s", def, structField.Name, typeField.typ, reason),
+								Kind:   InvalidTag,
+								Struct: typeField.typ,
+								Field:  structField.Name,
+								Path:   tag,
+								Err:    err,
+							}
+						}
+					}
+					var checks []check
+					if err == nil {
+						if checks, err = compileChecks(opts, structField.Type); err != nil {
+							err = ViewError{
+								Reason: fmt.Sprintf("invalid validation option on field %s of %s: %s", structField.Name, typeField.typ, err),
+								Kind:   InvalidTag,
+								Struct: typeField.typ,
+								Field:  structField.Name,
+								Path:   tag,
+								Err:    err,
+							}
+						}
 					}
 					fields = append(fields, field{
 						name:     name,
+						goName:   structField.Name,
 						path:     path,
+						elems:    elems,
+						leaf:     leaf,
+						aliases:  aliases,
+						err:      err,
 						tag:      tagged,
 						index:    index,
 						typ:      structFieldType,
 						convert:  opts.Contains("convert"),
 						optional: opts.Contains("optional"),
+						nullable: opts.Contains("nullable"),
 						isPtr:    isPtr,
-						//mutator:  makeMutator(structFieldType),
+						def:      defValue,
+						checks:   checks,
+						format:   format,
+						layout:   layout,
+
+						unmarshaler: unmarshalerOf(structFieldType),
+
+						mutable:        mutable,
+						mutatorFactory: mutatorFactory,
 					})
-					if count[typeField.typ] > 1 {
+					if count[key] > 1 {
 						// If there were multiple instances, add a second,
 						// so that the annihilation code will see a duplicate.
 						// It only cares about the distinction between 1 or 2,
@@ -309,9 +910,32 @@ func getFields(t reflect.Type) []field {
 					continue
 				}
 
-				// Record new anonymous struct to explore in next round.
-				nextCount[structFieldType]++
-				if nextCount[structFieldType] == 1 {
+				if len(alternatives) > 0 {
+					fields = append(fields, field{
+						name:   name,
+						goName: structField.Name,
+						path:   path,
+						index:  index,
+						typ:    structFieldType,
+						err: ViewError{
+							Reason: fmt.Sprintf("invalid views tag on field %s of %s: path aliases are not supported on inlined structs", structField.Name, typeField.typ),
+							Kind:   InvalidTag,
+							Struct: typeField.typ,
+							Field:  structField.Name,
+							Path:   tag,
+						},
+					})
+					continue
+				}
+
+				// Record new anonymous struct to explore in next round,
+				// under its tagged path if it has one.
+				if name != "" {
+					path = append(path, name)
+				}
+				embedded := embedKey{structFieldType, strings.Join(path, ".")}
+				nextCount[embedded]++
+				if nextCount[embedded] == 1 {
 					next = append(next, field{name: structFieldType.Name(), path: path, index: index, typ: structFieldType})
 				}
 			}
@@ -320,7 +944,154 @@ func getFields(t reflect.Type) []field {
 
 	// TODO: Sort by path depth so that we don't recurse into the map structure more
 	// than necessary.
-	return fields
+	return dominantFields(t, fields)
+}
+
+// dominantFields resolves fields that map to the same document path using
+// the rules encoding/json applies to names: the shallowest fields win, then
+// the tagged fields at that depth. Several fields of the same struct may
+// view the same path, but fields left from different embedded structs
+// cannot be told apart and are dropped, like encoding/json does. Each such
+// conflict is returned as an error. Fields keep their order otherwise.
+func dominantFields(t reflect.Type, fields []field) ([]field, ViewErrors) {
+	groups := map[string][]int{}
+	for i := range fields {
+		key := fields[i].key()
+		groups[key] = append(groups[key], i)
+	}
+
+	out := fields[:0:0]
+	var conflicts ViewErrors
+	for i := range fields {
+		group := groups[fields[i].key()]
+		if len(group) == 1 {
+			out = append(out, fields[i])
+			continue
+		}
+		if group[0] != i {
+			continue
+		}
+
+		depth := len(fields[group[0]].index)
+		for _, j := range group {
+			if d := len(fields[j].index); d < depth {
+				depth = d
+			}
+		}
+		var shallowest, tagged []int
+		for _, j := range group {
+			if len(fields[j].index) == depth {
+				shallowest = append(shallowest, j)
+				if fields[j].tag {
+					tagged = append(tagged, j)
+				}
+			}
+		}
+		if !sameStruct(fields, shallowest) && len(tagged) > 0 {
+			shallowest = tagged
+		}
+		if sameStruct(fields, shallowest) {
+			for _, j := range shallowest {
+				out = append(out, fields[j])
+			}
+			continue
+		}
+
+		var names []string
+		seen := map[string]bool{}
+		for _, j := range shallowest {
+			if name := goPath(t, fields[j].index); !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		conflict := fields[shallowest[0]]
+		reason := fmt.Sprintf("ambiguous fields %s of %s at path '%s'", strings.Join(names, " and "), t, conflict.key())
+		if len(names) == 1 {
+			reason = fmt.Sprintf("field %s of %s is promoted more than once to path '%s'", names[0], t, conflict.key())
+		}
+		conflicts = append(conflicts, ViewError{
+			Reason: reason,
+			Kind:   DuplicatePath,
+			Struct: t,
+			Field:  conflict.goName,
+			Path:   conflict.key(),
+		})
+	}
+	return out, conflicts
+}
+
+// sameStruct reports whether the fields at the given positions are all
+// declared in the same struct. A field listed twice is promoted from two
+// embedded structs of the same type, so it is not.
+func sameStruct(fields []field, positions []int) bool {
+	owners := map[string]bool{}
+	indexes := map[string]bool{}
+	for _, j := range positions {
+		index := fields[j].index
+		owners[fmt.Sprint(index[:len(index)-1])] = true
+		indexes[fmt.Sprint(index)] = true
+	}
+	return len(owners) == 1 && len(indexes) == len(positions)
+}
+
+// key returns the path the field maps to, relative to its struct.
+func (f *field) key() string {
+	return strings.Join(append(append([]string{}, f.path...), f.name), ".")
+}
+
+// goPath returns the selector of the field at index in t, such as
+// "Metadata.Name" for a field promoted from an embedded struct.
+func goPath(t reflect.Type, index []int) string {
+	names := make([]string, len(index))
+	for i, x := range index {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		f := t.Field(x)
+		names[i], t = f.Name, f.Type
+	}
+	return strings.Join(names, ".")
+}
+
+// onChain reports whether typ is root or one of the structs embedded on the
+// way to the field at index.
+func onChain(root reflect.Type, index []int, typ reflect.Type) bool {
+	t := root
+	for i := 0; ; i++ {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == typ {
+			return true
+		}
+		if i == len(index)-1 {
+			return false
+		}
+		t = t.Field(index[i]).Type
+	}
+}
+
+// An embedKey identifies an embedded struct explored by getFields.
+type embedKey struct {
+	typ  reflect.Type
+	path string
+}
+
+// isInline reports whether the fields of an embedded struct of type t are
+// promoted into the parent's field plan. Untagged embedded structs are
+// always inlined, at the parent's path. A tagged one is inlined under its
+// tagged path if it has the inline option or no options at all, unless it
+// fills itself like a Mutable[T] or a type with an unmarshaler. Otherwise it
+// is filled as a named field.
+func isInline(t reflect.Type, name string, opts tagOptions) bool {
+	if name == "" {
+		return true
+	}
+	if opts.Contains("inline") {
+		return true
+	}
+	return opts == "" && unmarshalerOf(t) == noUnmarshaler && !reflect.PointerTo(t).Implements(mutableBinderType)
 }
 
 func isValidTag(s string) bool {
@@ -335,37 +1106,145 @@ func isValidTag(s string) bool {
 type tagOptions string
 
 // parseTag splits a struct field's views tag into its name and
-// comma-separated options.
+// comma-separated options. Only the first of several paths separated by '|'
+// is returned, see aliasesOf.
 func parseTag(tag string) (string, []string, tagOptions) {
 	if len(tag) == 0 {
 		return "", []string{}, tagOptions("")
 	}
+	opts := tagOptions("")
+	if idx := strings.Index(tag, ","); idx != -1 {
+		tag, opts = tag[:idx], tagOptions(tag[idx+1:])
+	}
+	if idx := strings.Index(tag, "|"); idx != -1 {
+		tag = tag[:idx]
+	}
+	path := splitPath(tag)
+	return path[len(path)-1], path[:len(path)-1], opts
+}
+
+// aliasesOf returns the paths after the first in a views tag listing
+// alternatives, as in "user_id|userId|legacy.uid".
+func aliasesOf(tag string) []string {
 	if idx := strings.Index(tag, ","); idx != -1 {
-		path := strings.Split(tag[:idx], ".")
-		return path[len(path)-1], path[:len(path)-1], tagOptions(tag[idx+1:])
+		tag = tag[:idx]
 	}
-	path := strings.Split(tag, ".")
-	return path[len(path)-1], path[:len(path)-1], tagOptions("")
+	paths := strings.Split(tag, "|")
+	return paths[1:]
+}
+
+// An alias is an alternative path for a field, relative to its struct.
+type alias struct {
+	name  string
+	path  []string
+	elems []pathElem
+	leaf  pathElem
+}
+
+// parseAlias parses an alternative path of a field of a struct embedded at
+// prefix.
+func parseAlias(s string, prefix []string) (alias, error) {
+	if s == "" {
+		return alias{}, ViewError{Reason: "empty path alias", Kind: InvalidPath}
+	}
+	segments := splitPath(s)
+	a := alias{
+		name: segments[len(segments)-1],
+		path: append(append([]string{}, prefix...), segments[:len(segments)-1]...),
+	}
+	var err error
+	if a.elems, err = parsePath(a.path); err != nil {
+		return alias{}, err
+	}
+	if a.leaf, err = parsePathElem(a.name); err != nil {
+		return alias{}, err
+	}
+	return a, nil
 }
 
 // Contains reports whether a comma-separated list of options
 // contains a particular substr flag. substr must be surrounded by a
 // string boundary or commas.
 func (o tagOptions) Contains(optionName string) bool {
-	if len(o) == 0 {
-		return false
+	for _, opt := range o.List() {
+		if opt == optionName {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the value of an option of the form name=value.
+func (o tagOptions) Value(name string) (string, bool) {
+	for _, opt := range o.List() {
+		if strings.HasPrefix(opt, name+"=") {
+			return opt[len(name)+1:], true
+		}
 	}
+	return "", false
+}
+
+// List splits the options. A comma inside brackets of a value does not end
+// the option, so "pattern=^a{1,3}$" is a single option, and the default
+// option extends to the end of the tag.
+func (o tagOptions) List() []string {
+	var list []string
 	s := string(o)
 	for s != "" {
-		var next string
-		i := strings.Index(s, ",")
-		if i >= 0 {
-			s, next = s[:i], s[i+1:]
+		if strings.HasPrefix(s, "default=") {
+			return append(list, s)
 		}
-		if s == optionName {
-			return true
+		opt := s
+		if i := strings.Index(s, ","); i >= 0 {
+			opt, s = s[:i], s[i+1:]
+		} else {
+			s = ""
+		}
+		if n := len(list); n > 0 && unbalanced(list[n-1]) {
+			list[n-1] += "," + opt
+			continue
 		}
-		s = next
+		list = append(list, opt)
 	}
-	return false
+	return list
+}
+
+// unbalanced reports whether s opens more brackets than it closes, ignoring
+// brackets escaped with a backslash and brackets inside a character class
+// such as "[(]".
+func unbalanced(s string) bool {
+	depth, class := 0, false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+		case class:
+			if c == ']' {
+				class = false
+				depth--
+			}
+		case c == '[':
+			class = true
+			depth++
+			// A ']' right after the opening bracket, or after a '^' that
+			// negates the class, is part of the class.
+			if i+1 < len(s) && s[i+1] == '^' {
+				i++
+			}
+			if i+1 < len(s) && s[i+1] == ']' {
+				i++
+			}
+		case c == '(' || c == '{':
+			depth++
+		case c == ')' || c == '}':
+			depth--
+		}
+	}
+	return depth > 0
+}
+
+// Default returns the value of the default option. It must be the last
+// option, so the value extends to the end of the tag and may contain commas.
+func (o tagOptions) Default() (string, bool) {
+	return o.Value("default")
 }
@@ -24,6 +24,7 @@ package views
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	. "gopkg.in/check.v1"
 	"reflect"
@@ -81,26 +82,72 @@ func (s *ViewsSuite) TestGetContainer(c *C) {
 	}`)
 
 	data := s.getData(validData)
-	container, err := getContainer([]string{}, data)
-	c.Assert(container, DeepEquals, data)
+	container, err := getContainer([]string{}, MapContainer(data))
+	c.Assert(container, DeepEquals, MapContainer(data))
 	c.Assert(err, IsNil)
 
-	container, err = getContainer([]string{"b", "c"}, data)
+	container, err = getContainer([]string{"b", "c"}, MapContainer(data))
 	c.Assert(container, IsNil)
 	c.Assert(err, ErrorMatches, ".*no such key 'b' at index 0.*")
 
-	container, err = getContainer([]string{"a", "b", "c", "d"}, data)
+	container, err = getContainer([]string{"a", "b", "c", "d"}, MapContainer(data))
 	c.Assert(container, IsNil)
 	c.Assert(err, ErrorMatches, ".*for key 'c' at index 2 .* expected map\\[string\\]interface.*")
 
-	container, err = getContainer([]string{"a", "b"}, data)
-	c.Assert(container, DeepEquals, data["a"].(map[string]interface{})["b"])
+	container, err = getContainer([]string{"a", "b"}, MapContainer(data))
+	c.Assert(container, DeepEquals, MapContainer(data["a"].(map[string]interface{})["b"].(map[string]interface{})))
 	c.Assert(err, IsNil)
 
-	container, err = getContainer([]string{"a", "b", "d"}, data)
-	c.Assert(container["field1"], FitsTypeOf, "")
-	c.Assert(container["field1"].(string), Equals, "foobar")
+	container, err = getContainer([]string{"a", "b", "d"}, MapContainer(data))
+	field1, ok := container.Get("field1")
+	c.Assert(ok, Equals, true)
+	c.Assert(field1, Equals, "foobar")
+	c.Assert(err, IsNil)
+
+	container, err = getContainer([]string{"a", "b", "e[1]"}, MapContainer(data))
 	c.Assert(err, IsNil)
+	field2, _ := container.Get("field2")
+	c.Assert(field2, DeepEquals, []interface{}{"1", "2"})
+
+	container, err = getContainer([]string{"a", "b", "e[-2]"}, MapContainer(data))
+	c.Assert(err, IsNil)
+	field1, _ = container.Get("field1")
+	c.Assert(field1, Equals, "asdf")
+
+	container, err = getContainer([]string{"a", "b", "e[2]"}, MapContainer(data))
+	c.Assert(container, IsNil)
+	c.Assert(err, ErrorMatches, ".*index 2 out of range for key 'e' at index 2 in path 'a.b.e\\[2\\]'.*")
+
+	container, err = getContainer([]string{"a", "b", "d[0]"}, MapContainer(data))
+	c.Assert(container, IsNil)
+	c.Assert(err, ErrorMatches, ".*for key 'd' at index 2 .* expected \\[\\]interface\\{\\} not map.*")
+
+	container, err = getContainer([]string{"a", "b", "d", "field2[0]"}, MapContainer(data))
+	c.Assert(container, IsNil)
+	c.Assert(err, ErrorMatches, ".*for key 'field2\\[0\\]' at index 3 .* expected map\\[string\\]interface\\{\\} not string.*")
+
+	container, err = getContainer([]string{"a", "b", "e[x]"}, MapContainer(data))
+	c.Assert(container, IsNil)
+	c.Assert(err, ErrorMatches, ".*malformed index 'x' in path segment 'e\\[x\\]'.*")
+}
+
+func (s *ViewsSuite) TestParsePathElem(c *C) {
+	elem, err := parsePathElem("foo")
+	c.Assert(err, IsNil)
+	c.Assert(elem, DeepEquals, pathElem{raw: "foo", key: "foo"})
+
+	elem, err = parsePathElem("foo[1][-2]")
+	c.Assert(err, IsNil)
+	c.Assert(elem, DeepEquals, pathElem{raw: "foo[1][-2]", key: "foo", indexes: []int{1, -2}})
+
+	elem, err = parsePathElem("[3]")
+	c.Assert(err, IsNil)
+	c.Assert(elem, DeepEquals, pathElem{raw: "[3]", key: "", indexes: []int{3}})
+
+	for _, bad := range []string{"foo]", "foo[1", "foo[1]x", "foo[]"} {
+		_, err = parsePathElem(bad)
+		c.Assert(err, NotNil, Commentf("segment %q", bad))
+	}
 }
 
 func (s *ViewsSuite) TestParseTag(c *C) {
@@ -132,6 +179,74 @@ func (s *ViewsSuite) TestParseTag(c *C) {
 	c.Assert(options, Equals, tagOptions("convert,omit"))
 	c.Assert(options.Contains("convert"), Equals, true)
 	c.Assert(options.Contains("omit"), Equals, true)
+
+	name, path, options = parseTag("a.user_id|a.userId|uid,oneof=a|b")
+	c.Assert(name, Equals, "user_id")
+	c.Assert(path, DeepEquals, []string{"a"})
+	c.Assert(options, Equals, tagOptions("oneof=a|b"))
+	c.Assert(aliasesOf("a.user_id|a.userId|uid,oneof=a|b"), DeepEquals, []string{"a.userId", "uid"})
+	c.Assert(aliasesOf("a.user_id,oneof=a|b"), HasLen, 0)
+}
+
+type aliasView struct {
+	UserID  string          `views:"a.b.user_id|a.b.userId|legacy.uid"`
+	Count   int64           `views:"count|total,convert,min=1"`
+	Mutable Mutable[string] `views:"name|legacy.name"`
+	Note    string          `views:"note|remark,optional"`
+	Level   string          `views:"level|lvl,default=info"`
+}
+
+func (s *ViewsSuite) TestFillAliases(c *C) {
+	data := s.getData([]byte(`
+	{
+		"a": {"b": {"userId": "u2"}},
+		"legacy": {"uid": "u3", "name": "old"},
+		"count": 2.5,
+		"total": 7
+	}`))
+	out := aliasView{}
+	c.Assert(Fill(&out, "", data), IsNil)
+	c.Assert(out.UserID, Equals, "u2")
+	c.Assert(out.Count, Equals, int64(2))
+	c.Assert(out.Mutable.Get(), Equals, "old")
+	c.Assert(out.Note, Equals, "")
+	c.Assert(out.Level, Equals, "info")
+
+	// Mutables stay bound to the path they were found at, and Store writes
+	// to the first path.
+	out.Mutable.Set("older")
+	c.Assert(data["legacy"].(map[string]interface{})["name"], Equals, "older")
+	out.UserID = "u4"
+	c.Assert(Store(&out, "", data), IsNil)
+	c.Assert(data["a"].(map[string]interface{})["b"].(map[string]interface{})["user_id"], Equals, "u4")
+	c.Assert(data["a"].(map[string]interface{})["b"].(map[string]interface{})["userId"], Equals, "u2")
+	c.Assert(data["level"], Equals, "info")
+
+	// Errors carry the path the value was found at.
+	data = s.getData([]byte(`{"legacy": {"uid": 3}, "total": 0, "name": "n"}`))
+	err := FillAll(&aliasView{}, "", data)
+	c.Assert(err, ErrorMatches, "view error - 2 problems: cannot assign or convert 'float64' to 'string' at path 'legacy.uid' .*; validation 'min=1' failed for .total: .*")
+
+	data = s.getData([]byte(`{"a": {}, "count": 1, "name": "n"}`))
+	err = Fill(&aliasView{}, "", data)
+	c.Assert(err, ErrorMatches, "view error - could not find any of a.b.user_id, a.b.userId, legacy.uid in container")
+	var viewErr ViewError
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Kind, Equals, MissingKey)
+	c.Assert(viewErr.Path, Equals, "a.b.user_id")
+
+	data = s.getData([]byte(`{"count": 1}`))
+	err = FillAll(&aliasView{}, "", data)
+	c.Assert(err, ErrorMatches, "view error - 2 problems: could not find any of a.b.user_id, a.b.userId, legacy.uid in container; could not find any of .name, legacy.name in container")
+
+	var bad struct {
+		Field string `views:"a||b"`
+	}
+	c.Assert(Fill(&bad, "", data), ErrorMatches, "view error - invalid views tag on field Field of .*: empty path alias")
+	var inlined struct {
+		leafView `views:"x|y"`
+	}
+	c.Assert(Fill(&inlined, "", data), ErrorMatches, ".*path aliases are not supported on inlined structs")
 }
 
 type subStruct struct {
@@ -151,7 +266,7 @@ type testView struct {
 	FieldStringValueOptional string        `views:",optional"`
 	FieldStringMutableValue  MutableString `views:"FieldStringValue"`
 	FieldBoolValue           bool          `views:"a.b.f"`
-	//FieldBoolMutable        MutableBool `views:"a.b.c"`
+	FieldBoolMutable         MutableBool   `views:"a.b.f"`
 	Struct                  subStruct      `views:"a.b.d"`
 	Structs                 []subStruct    `views:"a.e"`
 	GenericStructsValue     []interface{}  `views:"a.e"`
@@ -221,13 +336,15 @@ func (s *ViewsSuite) TestFillFromMap(c *C) {
 				"f": true
 			},
 			"e": [{
-				"field1": "asdf"
+				"field1": "asdf",
+				"field2": []
 			},{
+				"field1": "qwer",
 				"field2": ["1", "2"]
 			}],
 			"g": {
 				"f": false,
-				"a": { "e": [], "b": { "c": 5000, "d":[], "f":true  } } ,
+				"a": { "e": [], "b": { "c": 5000, "d":{ "field1": "bazbar", "field2": [] }, "f":true  } } ,
 				"FieldStringValue": "barbaz"
 			}
 		},
@@ -246,6 +363,14 @@ func (s *ViewsSuite) TestFillFromMap(c *C) {
 	c.Assert(out.FieldStringValueOptional, Equals, "foobar2")
 	c.Assert(out.FieldStringValueIgnore, Equals, "")
 	c.Assert(out.FieldBoolValue, Equals, true)
+	c.Assert(out.FieldBoolMutable.Get(), Equals, true)
+	c.Assert(out.Structs, HasLen, 2)
+	c.Assert(out.Structs[1].Field2, DeepEquals, []interface{}{"1", "2"})
+	c.Assert(out.GenericStructsValue, HasLen, 2)
+	c.Assert(*out.Struct.Field1, Equals, "foobar")
+	c.Assert(*out.Structs[0].Field1, Equals, "asdf")
+	c.Assert(out.GenericStructsReference, NotNil)
+	c.Assert(*out.GenericStructsReference, DeepEquals, out.GenericStructsValue)
 
 	// try changing a value inside data
 	out.FieldFloatMutable.Set(float64(4000))
@@ -266,6 +391,555 @@ func (s *ViewsSuite) TestFillFromMap(c *C) {
 	c.Assert(out.FieldStringValueIgnore, Equals, "")
 }
 
+type innerView struct {
+	Count    int64   `views:"count,convert"`
+	Name     string  `views:"name"`
+	Missing  string  `views:"missing,optional"`
+	Deeper   leafView
+	Weighted float64 `views:"weights.w"`
+}
+
+type leafView struct {
+	Leaf string `views:"leaf"`
+}
+
+type outerView struct {
+	Title string    `views:"title"`
+	Inner innerView `views:"x.inner"`
+}
+
+func (s *ViewsSuite) TestFillSubStruct(c *C) {
+	validData := []byte(`
+	{
+		"root": {
+			"title": "outer",
+			"x": {
+				"inner": {
+					"count": 12.5,
+					"name": "innerName",
+					"Deeper": { "leaf": "green" },
+					"weights": { "w": 0.5 }
+				}
+			}
+		}
+	}`)
+	data := s.getData(validData)
+	out := outerView{}
+	err := Fill(&out, "root", data)
+	c.Assert(err, IsNil)
+	c.Assert(out.Title, Equals, "outer")
+	c.Assert(out.Inner.Count, Equals, int64(12))
+	c.Assert(out.Inner.Name, Equals, "innerName")
+	c.Assert(out.Inner.Missing, Equals, "")
+	c.Assert(out.Inner.Deeper.Leaf, Equals, "green")
+	c.Assert(out.Inner.Weighted, Equals, 0.5)
+
+	delete(data["root"].(map[string]interface{})["x"].(map[string]interface{})["inner"].(map[string]interface{})["Deeper"].(map[string]interface{}), "leaf")
+	err = Fill(&outerView{}, "root", data)
+	c.Assert(err, ErrorMatches, ".*could not find root.x.inner.Deeper.leaf in container.*")
+
+	data = s.getData(validData)
+	delete(data["root"].(map[string]interface{})["x"].(map[string]interface{})["inner"].(map[string]interface{}), "weights")
+	err = Fill(&outerView{}, "root", data)
+	c.Assert(err, ErrorMatches, ".*no such key 'weights' at index 3 in path 'root.x.inner.weights'.*")
+
+	data = s.getData(validData)
+	data["root"].(map[string]interface{})["x"].(map[string]interface{})["inner"] = "flat"
+	err = Fill(&outerView{}, "root", data)
+	c.Assert(err, ErrorMatches, ".*cannot fill 'views.innerView' from 'string' at path 'root.x.inner' in struct of type views.outerView.*")
+}
+
+type metadataView struct {
+	Name    string            `views:"name"`
+	Labels  map[string]string `views:"labels,optional"`
+	Version int64             `views:"meta.version,convert"`
+}
+
+type OwnerView struct {
+	Owner string `views:"owner"`
+}
+
+type embeddedView struct {
+	metadataView `views:"metadata,inline"`
+	*OwnerView   `views:"spec.owner"`
+	leafView
+	Status  metadataView `views:"status"`
+	Pointed *leafView    `views:"pointed,optional"`
+}
+
+func (s *ViewsSuite) TestFillEmbeddedPrefix(c *C) {
+	data := s.getData([]byte(`
+	{
+		"metadata": {"name": "web", "meta": {"version": 3}},
+		"spec": {"owner": {"owner": "ops"}},
+		"status": {"name": "ready", "meta": {"version": 4}},
+		"leaf": "root"
+	}`))
+	out := embeddedView{}
+	c.Assert(Fill(&out, "", data), IsNil)
+	c.Assert(out.Name, Equals, "web")
+	c.Assert(out.Version, Equals, int64(3))
+	c.Assert(out.Owner, Equals, "ops")
+	c.Assert(out.Leaf, Equals, "root")
+	c.Assert(out.Status.Name, Equals, "ready")
+	c.Assert(out.Pointed, IsNil)
+
+	out.Name = "api"
+	out.Owner = "dev"
+	c.Assert(Store(&out, "", data), IsNil)
+	c.Assert(data["metadata"].(map[string]interface{})["name"], Equals, "api")
+	c.Assert(data["spec"].(map[string]interface{})["owner"].(map[string]interface{})["owner"], Equals, "dev")
+
+	delete(data["metadata"].(map[string]interface{}), "name")
+	c.Assert(Fill(&embeddedView{}, "", data), ErrorMatches, ".*could not find metadata.name in container.*")
+
+	// Embedded structs with other options are filled as named fields.
+	var optional struct {
+		OwnerView `views:"spec.owner,optional"`
+	}
+	c.Assert(Fill(&optional, "", data), IsNil)
+	c.Assert(optional.Owner, Equals, "dev")
+	c.Assert(getFields(reflect.TypeOf(optional))[0].goName, Equals, "OwnerView")
+}
+
+type Node struct {
+	Name  string `views:"name"`
+	*Node `views:"child"`
+}
+
+func (s *ViewsSuite) TestFillRecursiveEmbedded(c *C) {
+	data := s.getData([]byte(`{"name": "root", "child": {"name": "leaf"}}`))
+	out := Node{}
+	c.Assert(Fill(&out, "", data), IsNil)
+	c.Assert(out.Name, Equals, "root")
+	c.Assert(out.Node.Name, Equals, "leaf")
+	c.Assert(out.Node.Node, IsNil)
+	c.Assert(getFields(reflect.TypeOf(out)), HasLen, 2)
+}
+
+type NamedView struct {
+	Name string `views:"name"`
+	ID   string `views:"id"`
+}
+
+type TaggedView struct {
+	Title string `views:"Name"`
+	ID    string `views:"id"`
+}
+
+type UntaggedView struct {
+	Name string
+}
+
+type LeftView struct{ NamedView }
+type RightView struct{ NamedView }
+
+type dominantView struct {
+	Name string `views:"name,optional"`
+	NamedView
+	TaggedView
+	UntaggedView
+}
+
+type ambiguousView struct {
+	LeftView
+	RightView
+}
+
+func (s *ViewsSuite) TestFillDominantFields(c *C) {
+	data := s.getData([]byte(`
+	{
+		"name": "outer",
+		"Name": "title",
+		"id": "42"
+	}`))
+
+	// The shallowest field wins, then the tagged one, and ambiguous
+	// fields are left out.
+	out := dominantView{}
+	c.Assert(Fill(&out, "", data), IsNil)
+	c.Assert(out.Name, Equals, "outer")
+	c.Assert(out.NamedView.Name, Equals, "")
+	c.Assert(out.Title, Equals, "title")
+	c.Assert(out.UntaggedView.Name, Equals, "")
+	c.Assert(out.NamedView.ID, Equals, "")
+	c.Assert(out.TaggedView.ID, Equals, "")
+
+	err := CheckView(&out)
+	c.Assert(err, ErrorMatches, "view error - ambiguous fields NamedView.ID and TaggedView.ID of views.dominantView at path 'id'")
+	var viewErr ViewError
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Kind, Equals, DuplicatePath)
+	c.Assert(viewErr.Path, Equals, "id")
+
+	// The same struct embedded twice at the same depth conflicts with
+	// itself.
+	ambiguous := ambiguousView{}
+	ambiguous.LeftView.ID = "left"
+	c.Assert(Fill(&ambiguous, "", data), IsNil)
+	c.Assert(ambiguous.LeftView.Name, Equals, "")
+	stored := map[string]interface{}{}
+	c.Assert(Store(&ambiguous, "", stored), IsNil)
+	c.Assert(stored, HasLen, 0)
+	c.Assert(CheckView(ambiguous), ErrorMatches, "view error - 2 problems: field LeftView.NamedView.Name of views.ambiguousView is promoted more than once to path 'name'; .*")
+	c.Assert(CheckView(outerView{}), IsNil)
+}
+
+type sliceElemView struct {
+	Field1 string `views:"field1"`
+	Size   int    `views:"size,convert,optional"`
+}
+
+type sliceView struct {
+	Strings  []string        `views:"strings"`
+	Ints     []int64         `views:"ints,convert"`
+	Matrix   [][]float64     `views:"matrix"`
+	Elems    []sliceElemView `views:"b.e"`
+	Optional []string        `views:"optional,optional"`
+}
+
+func (s *ViewsSuite) TestFillSlices(c *C) {
+	validData := []byte(`
+	{
+		"a": {
+			"strings": ["x", "y", "z"],
+			"ints": [1, 2.5, 3],
+			"matrix": [[1, 2], [], [3]],
+			"b": {
+				"e": [
+					{ "field1": "one", "size": 10 },
+					{ "field1": "two" }
+				]
+			}
+		}
+	}`)
+	data := s.getData(validData)
+	out := sliceView{}
+	err := Fill(&out, "a", data)
+	c.Assert(err, IsNil)
+	c.Assert(out.Strings, DeepEquals, []string{"x", "y", "z"})
+	c.Assert(out.Ints, DeepEquals, []int64{1, 2, 3})
+	c.Assert(out.Matrix, DeepEquals, [][]float64{{1, 2}, {}, {3}})
+	c.Assert(out.Elems, DeepEquals, []sliceElemView{{"one", 10}, {"two", 0}})
+	c.Assert(out.Optional, IsNil)
+
+	data = s.getData(validData)
+	data["a"].(map[string]interface{})["strings"] = []interface{}{"x", 5.0}
+	err = Fill(&sliceView{}, "a", data)
+	c.Assert(err, ErrorMatches, ".*cannot assign or convert 'float64' to 'string' at path 'a.strings\\[1\\]' in struct of type views.sliceView.*")
+
+	data = s.getData(validData)
+	data["a"].(map[string]interface{})["strings"] = "x"
+	err = Fill(&sliceView{}, "a", data)
+	c.Assert(err, ErrorMatches, ".*cannot assign or convert 'string' to '\\[\\]string' at path 'a.strings'.*")
+
+	type elems struct {
+		Elems []sliceElemView `views:"a.b.e"`
+	}
+	data = s.getData(validData)
+	elemList := data["a"].(map[string]interface{})["b"].(map[string]interface{})["e"].([]interface{})
+	for i := 0; i < 2; i++ {
+		elemList = append(elemList, map[string]interface{}{"field1": "more"})
+	}
+	elemList = append(elemList, map[string]interface{}{"other": "missing"})
+	data["a"].(map[string]interface{})["b"].(map[string]interface{})["e"] = elemList
+	err = Fill(&elems{}, "", data)
+	c.Assert(err, ErrorMatches, ".*could not find a.b.e\\[4\\].field1 in container.*")
+}
+
+type pointerView struct {
+	Name    *string    `views:"name"`
+	Count   *int64     `views:"count,convert"`
+	Sub     *leafView  `views:"sub"`
+	Null    *string    `views:"null"`
+	Absent  *leafView  `views:"absent"`
+	Names   []*string  `views:"names"`
+	Nested  **float64  `views:"count"`
+	Generic *[]float64 `views:"list"`
+	Deep    *string    `views:"a.b.c"`
+}
+
+func (s *ViewsSuite) TestFillPointers(c *C) {
+	validData := []byte(`
+	{
+		"name": "",
+		"count": 0,
+		"sub": { "leaf": "green" },
+		"null": null,
+		"names": ["a", null, "c"],
+		"list": [1, 2]
+	}`)
+	data := s.getData(validData)
+	out := pointerView{}
+	err := Fill(&out, "", data)
+	c.Assert(err, IsNil)
+	c.Assert(out.Name, NotNil)
+	c.Assert(*out.Name, Equals, "")
+	c.Assert(out.Count, NotNil)
+	c.Assert(*out.Count, Equals, int64(0))
+	c.Assert(out.Sub, NotNil)
+	c.Assert(out.Sub.Leaf, Equals, "green")
+	c.Assert(out.Null, IsNil)
+	c.Assert(out.Absent, IsNil)
+	c.Assert(out.Names, HasLen, 3)
+	c.Assert(*out.Names[0], Equals, "a")
+	c.Assert(out.Names[1], IsNil)
+	c.Assert(*out.Names[2], Equals, "c")
+	c.Assert(**out.Nested, Equals, float64(0))
+	c.Assert(*out.Generic, DeepEquals, []float64{1, 2})
+	c.Assert(out.Deep, IsNil)
+
+	// A previously filled pointer is reset when the value becomes null.
+	data["name"] = nil
+	err = Fill(&out, "", data)
+	c.Assert(err, IsNil)
+	c.Assert(out.Name, IsNil)
+
+	data = s.getData(validData)
+	data["sub"] = map[string]interface{}{}
+	err = Fill(&pointerView{}, "", data)
+	c.Assert(err, ErrorMatches, ".*could not find sub.leaf in container.*")
+}
+
+func (s *ViewsSuite) TestFillIndexes(c *C) {
+	validData := []byte(`
+	{
+		"items": [{ "name": "first", "leaf": "l0" }, { "name": "second", "leaf": "l1" }],
+		"grid": [[1, 2], [3, 4]],
+		"a": { "b": { "e": [{ "field1": "x" }, { "field1": "y" }] } }
+	}`)
+	data := s.getData(validData)
+
+	type boundView struct {
+		Mutable MutableFloat `views:"grid[1][0]"`
+	}
+	bound := boundView{}
+	err := Fill(&bound, "", data)
+	c.Assert(err, IsNil)
+	c.Assert(bound.Mutable.Get(), Equals, float64(3))
+	bound.Mutable.Set(30)
+	c.Assert(data["grid"].([]interface{})[1].([]interface{})[0], Equals, float64(30))
+	data = s.getData(validData)
+
+	type view struct {
+		First     string     `views:"items[0].name"`
+		Last      string     `views:"a.b.e[-1].field1"`
+		Cell      float64    `views:"grid[1][0]"`
+		Missing   string     `views:"items[0].nickname,optional"`
+		Elem      leafView   `views:"items[1]"`
+		Remaining []leafView `views:"items"`
+	}
+	out := view{}
+	err = Fill(&out, "", data)
+	c.Assert(err, IsNil)
+	c.Assert(out.First, Equals, "first")
+	c.Assert(out.Last, Equals, "y")
+	c.Assert(out.Cell, Equals, float64(3))
+	c.Assert(out.Missing, Equals, "")
+	c.Assert(out.Elem.Leaf, Equals, "l1")
+	c.Assert(out.Remaining, DeepEquals, []leafView{{"l0"}, {"l1"}})
+
+	leaf := leafView{}
+	err = Fill(&leaf, "items[-1]", data)
+	c.Assert(err, IsNil)
+	c.Assert(leaf.Leaf, Equals, "l1")
+
+	type optional struct {
+		Optional string `views:"grid[7],optional"`
+	}
+	err = Fill(&optional{}, "", data)
+	c.Assert(err, IsNil)
+
+	type required struct {
+		Required float64 `views:"grid[0][7]"`
+	}
+	err = Fill(&required{}, "", data)
+	c.Assert(err, ErrorMatches, ".*could not find .grid\\[0\\]\\[7\\] in container.*")
+
+	type notASlice struct {
+		Name string `views:"a.b[0]"`
+	}
+	err = Fill(&notASlice{}, "", data)
+	c.Assert(err, ErrorMatches, ".*for key 'b' at path 'a.b\\[0\\]', expected \\[\\]interface\\{\\} not map.*")
+
+	type badTag struct {
+		Name string `views:"items[a].name"`
+	}
+	err = Fill(&badTag{}, "", data)
+	c.Assert(err, ErrorMatches, ".*invalid views tag on field Name of views.badTag: malformed index 'a' in path segment 'items\\[a\\]'.*")
+}
+
+type fillAllView struct {
+	Name    string          `views:"name"`
+	Count   int64           `views:"count"`
+	Missing string          `views:"missing"`
+	Nested  leafView        `views:"nested"`
+	Elems   []sliceElemView `views:"elems"`
+	Through string          `views:"name.inner"`
+	Fine    float64         `views:"fine"`
+}
+
+func (s *ViewsSuite) TestFillAll(c *C) {
+	data := s.getData([]byte(`
+	{
+		"name": 5,
+		"count": "ten",
+		"nested": { "leaf": false },
+		"elems": [{ "field1": "ok" }, { "field1": 3 }],
+		"fine": 1.5
+	}`))
+	out := fillAllView{}
+	err := FillAll(&out, "", data)
+	c.Assert(err, NotNil)
+	c.Assert(out.Fine, Equals, 1.5)
+	c.Assert(out.Elems[0].Field1, Equals, "ok")
+
+	var viewErrs ViewErrors
+	c.Assert(errors.As(err, &viewErrs), Equals, true)
+	c.Assert(viewErrs, HasLen, 6)
+
+	stringType := reflect.TypeOf("")
+	expected := []ViewError{
+		{Path: "name", Expected: stringType, Actual: reflect.TypeOf(0.0)},
+		{Path: "count", Expected: reflect.TypeOf(int64(0)), Actual: stringType},
+		{Path: "missing", Expected: stringType},
+		{Path: "nested.leaf", Expected: stringType, Actual: reflect.TypeOf(false)},
+		{Path: "elems[1].field1", Expected: stringType, Actual: reflect.TypeOf(0.0)},
+		{Path: "name", Expected: mapType, Actual: reflect.TypeOf(0.0)},
+	}
+	for i, viewErr := range viewErrs {
+		c.Assert(viewErr.Path, Equals, expected[i].Path)
+		c.Assert(viewErr.Expected, Equals, expected[i].Expected)
+		c.Assert(viewErr.Actual, Equals, expected[i].Actual)
+		c.Assert(viewErr.Reason, Not(Equals), "")
+	}
+	c.Assert(err, ErrorMatches, "view error - 6 problems: cannot assign or convert 'float64' to 'string' at path '.name'.*; could not find .missing in container;.*")
+
+	var viewErr ViewError
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr, Equals, viewErrs[0])
+	c.Assert(errors.Is(err, viewErrs[3]), Equals, true)
+
+	// Fill stops at the first failure.
+	err = Fill(&fillAllView{}, "", data)
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Path, Equals, "name")
+
+	err = FillAll(&fillAllView{}, "", map[string]interface{}{"name": "x", "count": int64(1), "missing": "", "nested": map[string]interface{}{"leaf": ""}, "elems": []interface{}{}, "fine": 0.0})
+	c.Assert(err, ErrorMatches, ".*for key 'name' at index 0 in path 'name', expected map.*")
+	err = FillAll(&leafView{}, "", map[string]interface{}{"leaf": "x"})
+	c.Assert(err, IsNil)
+}
+
+type nullView struct {
+	Optional  string                 `views:"null,optional"`
+	Nullable  float64                `views:"null,nullable"`
+	Pointer   *string                `views:"null"`
+	Any       interface{}            `views:"null"`
+	Slice     []string               `views:"null"`
+	Map       map[string]interface{} `views:"null"`
+	Struct    leafView               `views:"null,optional"`
+	Elems     []*leafView            `views:"elems"`
+	Mutable   MutableFloat           `views:"null,optional"`
+	Bound     MutableFloat           `views:"null,nullable"`
+	Generic   Mutable[int64]         `views:"null,optional"`
+	Strings   []string               `views:"strings,nullable"`
+	Interface []interface{}          `views:"strings"`
+}
+
+func (s *ViewsSuite) TestFillNulls(c *C) {
+	data := s.getData([]byte(`
+	{
+		"null": null,
+		"elems": [{ "leaf": "x" }, null],
+		"strings": ["a", null]
+	}`))
+	name := "set"
+	out := nullView{Optional: "kept", Nullable: 1, Pointer: &name, Any: 1, Slice: []string{}, Map: map[string]interface{}{}}
+	err := Fill(&out, "", data)
+	c.Assert(err, IsNil)
+	c.Assert(out.Optional, Equals, "kept")
+	c.Assert(out.Nullable, Equals, float64(0))
+	c.Assert(out.Pointer, IsNil)
+	c.Assert(out.Any, IsNil)
+	c.Assert(out.Slice, IsNil)
+	c.Assert(out.Map, IsNil)
+	c.Assert(out.Elems, DeepEquals, []*leafView{{"x"}, nil})
+	c.Assert(out.Mutable, IsNil)
+	c.Assert(out.Bound, NotNil)
+	_, ok := out.Bound.GetChecked()
+	c.Assert(ok, Equals, false)
+	out.Bound.Set(2)
+	c.Assert(data["null"], Equals, float64(2))
+	c.Assert(out.Strings, DeepEquals, []string{"a", ""})
+	c.Assert(out.Interface, DeepEquals, []interface{}{"a", nil})
+
+	data["null"] = nil
+	type required struct {
+		Required string `views:"null"`
+	}
+	var viewErr ViewError
+	err = Fill(&required{}, "", data)
+	c.Assert(err, ErrorMatches, ".*cannot assign null to 'string' at path '.null' in struct of type views.required.*")
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Kind, Equals, NullValue)
+	c.Assert(viewErr.Path, Equals, "null")
+
+	type element struct {
+		Strings []string `views:"strings"`
+	}
+	err = Fill(&element{}, "", data)
+	c.Assert(err, ErrorMatches, ".*cannot assign null to 'string' at path '.strings\\[1\\]'.*")
+
+	type structValue struct {
+		Struct leafView `views:"null"`
+	}
+	err = Fill(&structValue{}, "", data)
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Kind, Equals, NullValue)
+
+	type mutable struct {
+		Mutable MutableString `views:"null"`
+	}
+	err = Fill(&mutable{}, "", data)
+	c.Assert(err, ErrorMatches, ".*cannot assign null to 'views.MutableString' at path '.null'.*")
+
+	type generic struct {
+		Generic Mutable[int64] `views:"null"`
+	}
+	err = Fill(&generic{}, "", data)
+	c.Assert(err, ErrorMatches, ".*cannot assign null to 'views.Mutable\\[int64\\]' at path '.null'.*")
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Kind, Equals, NullValue)
+
+	// A null in the middle of a path is treated as missing.
+	type throughNull struct {
+		Optional string   `views:"null.b,optional"`
+		Pointer  *string  `views:"null.c"`
+		Indexed  []string `views:"null[0],optional"`
+	}
+	through := throughNull{}
+	c.Assert(Fill(&through, "", data), IsNil)
+	c.Assert(through.Pointer, IsNil)
+
+	type throughNullRequired struct {
+		Value string `views:"null.b"`
+	}
+	err = Fill(&throughNullRequired{}, "", data)
+	c.Assert(err, ErrorMatches, "view error - null value for key 'null' at index 0 in path 'null'")
+	c.Assert(errors.As(err, &viewErr), Equals, true)
+	c.Assert(viewErr.Kind, Equals, MissingKey)
+
+	p, err := ParsePath("null.b")
+	c.Assert(err, IsNil)
+	_, err = p.Get(data)
+	c.Assert(err, ErrorMatches, "view error - null value for key 'null' at index 0 in path 'null.b'")
+	p, err = ParsePath("null[0]")
+	c.Assert(err, IsNil)
+	_, err = p.Get(data)
+	c.Assert(err, ErrorMatches, "view error - null value for key 'null\\[0\\]' .*")
+}
+
 func (s *ViewsSuite) TestFillFromMapBad(c *C) {
 	type UnknownInterface interface {
 		Bar()
@@ -354,11 +1028,80 @@ func (s *ViewsSuite) TestExample(c *C) {
 
     b.E.Set(100.34)
 	c.Assert(b.E.Get(), Equals, 100.34)
-	container, err := getContainer([]string{"a","b"}, data)
+	container, err := getContainer([]string{"a","b"}, MapContainer(data))
 	c.Assert(err, IsNil)
-	f, ok := container["E"].(float64)
+	f, ok := container.Get("E")
 	c.Assert(ok, Equals, true)
 	c.Assert(f, Equals, 100.34)
 	
     // data["a]["b"]["E"] == 100.34
 }
+
+func (s *ViewsSuite) TestCachedFields(c *C) {
+	typ := reflect.TypeOf(testView{})
+	fields := cachedFields(typ)
+	uncached := getFields(typ)
+	c.Assert(fields, HasLen, len(uncached))
+	for i := range fields {
+		c.Assert(fields[i].name, Equals, uncached[i].name)
+		c.Assert(fields[i].index, DeepEquals, uncached[i].index)
+	}
+	c.Assert(&cachedFields(typ)[0], Equals, &fields[0])
+
+	for _, f := range fields {
+		switch f.typ {
+		case mutableFloatType, mutableStringType:
+			c.Assert(f.mutatorFactory, NotNil)
+		}
+	}
+}
+
+var benchmarkData = []byte(`
+{
+	"a": {
+		"b": {
+			"c": 2000.12354,
+			"d": {
+				"field1": "foobar"
+			},
+			"E": 432.1,
+			"tags": ["x", "y", "z"]
+		}
+	}
+}`)
+
+type benchmarkView struct {
+	C      int64    `views:"c,convert"`
+	Field1 string   `views:"d.field1"`
+	E      MutableFloat
+	Tags   []string `views:"tags"`
+}
+
+func BenchmarkFill(b *testing.B) {
+	data := make(map[string]interface{})
+	json.Unmarshal(benchmarkData, &data)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		out := benchmarkView{}
+		if err := Fill(&out, "a.b", data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetFields(b *testing.B) {
+	typ := reflect.TypeOf(benchmarkView{})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		getFields(typ)
+	}
+}
+
+func BenchmarkCachedFields(b *testing.B) {
+	typ := reflect.TypeOf(benchmarkView{})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cachedFields(typ)
+	}
+}